@@ -0,0 +1,81 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// alertTypes maps GitHub alert types to their titles and the octicons
+// shown in them.
+var alertTypes = map[string]struct{ title, octicon string }{
+	"note":      {"Note", "info"},
+	"tip":       {"Tip", "light-bulb"},
+	"important": {"Important", "report"},
+	"warning":   {"Warning", "alert"},
+	"caution":   {"Caution", "stop"},
+}
+
+// alertMarkerRE matches the marker on the first line of an alert blockquote.
+var alertMarkerRE = regexp.MustCompile(`(?i)^\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)\][ \t]*(?:\n|$)`)
+
+// blockquote renders a blockquote, or a GitHub alert box if it starts
+// with a marker line like "[!NOTE]" and has content after it.
+func (r *renderer) blockquote(w io.Writer, node *bf.Node, entering bool) bf.WalkStatus {
+	if !entering {
+		// BlockQuote nodes have no literal, so it holds the type of alerts.
+		if len(node.Literal) == 0 {
+			return r.HTMLRenderer.RenderNode(w, node, entering)
+		}
+		io.WriteString(w, "</div>\n")
+		return bf.GoToNext
+	}
+
+	kind := alertKind(node)
+	if kind == "" {
+		return r.HTMLRenderer.RenderNode(w, node, entering)
+	}
+	node.Literal = []byte(kind)
+	if node.Prev != nil {
+		io.WriteString(w, "\n")
+	}
+	fmt.Fprintf(w, `<div class="markdown-alert markdown-alert-%s"><p class="markdown-alert-title"><span class="octicon octicon-%s"></span>%s</p>`+"\n",
+		kind, alertTypes[kind].octicon, alertTypes[kind].title)
+	return bf.GoToNext
+}
+
+// alertKind returns the lowercase alert type of a blockquote node,
+// removing the marker from it, or "" if it isn't an alert.
+func alertKind(node *bf.Node) string {
+	para := node.FirstChild
+	if para == nil || para.Type != bf.Paragraph || para.FirstChild == nil || para.FirstChild.Type != bf.Text {
+		return ""
+	}
+	text := para.FirstChild
+	m := alertMarkerRE.FindSubmatchIndex(text.Literal)
+	if m == nil {
+		return ""
+	}
+	rest := text.Literal[m[1]:]
+	if len(rest) == 0 && text.Next != nil && text.Next.Type == bf.Hardbreak {
+		text.Next.Unlink()
+	}
+	if len(rest) == 0 && text.Next == nil && para.Next == nil {
+		// Alerts need content.
+		return ""
+	}
+
+	kind := strings.ToLower(string(text.Literal[m[2]:m[3]]))
+	text.Literal = bytes.TrimLeft(rest, " \t")
+	if len(text.Literal) == 0 {
+		text.Unlink()
+	}
+	if para.FirstChild == nil {
+		para.Unlink()
+	}
+	return kind
+}
@@ -0,0 +1,45 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestAlerts(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "> [!NOTE]\n> Useful *information*.\n",
+			want: `<div class="markdown-alert markdown-alert-note"><p class="markdown-alert-title"><span class="octicon octicon-info"></span>Note</p>` + "\n" +
+				"<p>Useful <em>information</em>.</p>\n</div>\n",
+		},
+		{
+			// The marker is case-insensitive, and may be followed by a blank line.
+			text: "> [!caution]\n>\n> Risky.\n",
+			want: `<div class="markdown-alert markdown-alert-caution"><p class="markdown-alert-title"><span class="octicon octicon-stop"></span>Caution</p>` + "\n" +
+				"<p>Risky.</p>\n</div>\n",
+		},
+		{
+			// Markers without content, or not on a line of their own, are left as is.
+			text: "> [!TIP]\n",
+			want: "<blockquote>\n<p>[!TIP]</p>\n</blockquote>\n",
+		},
+		{
+			text: "> [!TIP] Text.\n",
+			want: "<blockquote>\n<p>[!TIP] Text.</p>\n</blockquote>\n",
+		},
+		{
+			text: "> [!UNKNOWN]\n> Text.\n",
+			want: "<blockquote>\n<p>[!UNKNOWN]\nText.</p>\n</blockquote>\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
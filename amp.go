@@ -0,0 +1,100 @@
+package github_flavored_markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Default dimensions of AMP images, which must be given explicitly.
+// The real dimensions of images aren't known when rendering, so images get
+// a responsive layout with this aspect ratio unless the source specifies
+// a width and height.
+const ampImageWidth, ampImageHeight = "16", "9"
+
+// ampForbidden are elements that AMP HTML doesn't allow in content.
+var ampForbidden = map[atom.Atom]bool{
+	atom.Applet: true, atom.Audio: true, atom.Base: true, atom.Embed: true,
+	atom.Form: true, atom.Frame: true, atom.Frameset: true, atom.Iframe: true,
+	atom.Input: true, atom.Object: true, atom.Param: true, atom.Script: true,
+	atom.Style: true, atom.Video: true,
+}
+
+// ampPolicy is the sanitization policy used for AMP output. It is applied
+// after ampify, so no <img> elements remain.
+var ampPolicy = newAMPPolicy()
+
+func newAMPPolicy() *bluemonday.Policy {
+	p := NewPolicy()
+	p.AllowAttrs("src").Matching(regexp.MustCompile(`^(https?:)?//\S*$|^[^:\s]*$`)).OnElements("amp-img")
+	p.AllowAttrs("alt", "title").OnElements("amp-img")
+	p.AllowAttrs("width", "height").Matching(regexp.MustCompile(`^[0-9]+$`)).OnElements("amp-img")
+	p.AllowAttrs("layout").Matching(regexp.MustCompile(`^responsive$`)).OnElements("amp-img")
+	return p
+}
+
+// ampify rewrites an HTML fragment for AMP. <img> elements become <amp-img>
+// elements with the required dimensions and layout, elements AMP forbids
+// are removed, as are event handler and style attributes.
+func ampify(b []byte) []byte {
+	return rewriteHTML(b, ampifyNode)
+}
+
+func ampifyNode(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && ampForbidden[c.DataAtom] {
+			n.RemoveChild(c)
+		} else {
+			ampifyNode(c)
+		}
+		c = next
+	}
+	if n.Type != html.ElementNode {
+		return
+	}
+
+	var attr []html.Attribute
+	var width, height string
+	for _, a := range n.Attr {
+		switch {
+		case strings.HasPrefix(a.Key, "on") || a.Key == "style":
+			continue
+		case a.Key == "width" && n.DataAtom == atom.Img:
+			width = a.Val
+			continue
+		case a.Key == "height" && n.DataAtom == atom.Img:
+			height = a.Val
+			continue
+		}
+		attr = append(attr, a)
+	}
+	n.Attr = attr
+
+	if n.DataAtom == atom.Img {
+		if !isDigits(width) || !isDigits(height) {
+			width, height = ampImageWidth, ampImageHeight
+		}
+		n.Data, n.DataAtom = "amp-img", 0
+		n.Attr = append(n.Attr,
+			html.Attribute{Key: "width", Val: width},
+			html.Attribute{Key: "height", Val: height},
+			html.Attribute{Key: "layout", Val: "responsive"},
+		)
+	}
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
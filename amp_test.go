@@ -0,0 +1,41 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestAMP(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "![logo](https://example.com/logo.png)",
+			want: `<p><amp-img src="https://example.com/logo.png" alt="logo" width="16" height="9" layout="responsive"></amp-img></p>` + "\n",
+		},
+		{
+			// Dimensions of raw images are kept, disallowed attributes are stripped.
+			text: `<img src="/a.png" width="40" height="30" onclick="steal()" style="border: 0">`,
+			want: `<p><amp-img src="/a.png" width="40" height="30" layout="responsive"></amp-img></p>` + "\n",
+		},
+		{
+			// Unsafe image URLs are removed.
+			text: `<img src="javascript:alert(1)">`,
+			want: `<p><amp-img width="16" height="9" layout="responsive"></amp-img></p>` + "\n",
+		},
+		{
+			// Inputs, such as task list checkboxes, are not allowed in AMP.
+			text: `<input type="checkbox" checked="" disabled=""> Done.`,
+			want: "<p> Done.</p>\n",
+		},
+	}
+
+	for _, test := range tests {
+		opts := github_flavored_markdown.Options{AMP: true}
+		if got := string(github_flavored_markdown.MarkdownOptions([]byte(test.text), opts)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
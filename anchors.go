@@ -0,0 +1,120 @@
+package github_flavored_markdown
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// userContentPrefix is the prefix GitHub adds to the anchor names in user content.
+const userContentPrefix = "user-content-"
+
+// anchorNamer generates heading anchor names that are unique within a document.
+// Like GitHub, a repeated name gets a "-1", "-2", ... suffix.
+type anchorNamer struct {
+	github bool                      // Name anchors exactly like GitHub. See Options.GitHubAnchors.
+	slug   func(title string) string // Custom slug function, if any. See Options.Slug.
+	used   map[string]int            // Anchor name -> last suffix used for it.
+}
+
+func newAnchorNamer(opts Options) anchorNamer {
+	return anchorNamer{github: opts.GitHubAnchors, slug: opts.Slug}
+}
+
+// name returns the anchor name for a heading with the given plain text title.
+func (a *anchorNamer) name(title string) string {
+	if a.used == nil {
+		a.used = make(map[string]int)
+	}
+	if a.github {
+		return a.githubName(title)
+	}
+	name := slug(title)
+	if a.slug != nil {
+		name = a.slug(title)
+	}
+	if n, ok := a.used[name]; ok {
+		for {
+			n++
+			candidate := fmt.Sprintf("%s-%d", name, n)
+			if _, ok := a.used[candidate]; !ok {
+				a.used[name] = n
+				name = candidate
+				break
+			}
+		}
+	}
+	a.used[name] = 0
+	return name
+}
+
+// slug returns the anchor name for a heading title: its letters, marks and
+// numbers, from any script, in lowercase, with each run of other characters
+// between them replaced by a dash. Titles without any, such as those made of
+// emoji only, get "heading".
+func slug(title string) string {
+	var name []rune
+	dash := false
+	for _, r := range title {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsNumber(r):
+			if dash && len(name) > 0 {
+				name = append(name, '-')
+			}
+			dash = false
+			name = append(name, unicode.ToLower(r))
+		default:
+			dash = true
+		}
+	}
+	if len(name) == 0 {
+		return "heading"
+	}
+	return string(name)
+}
+
+// anchorHref returns the href of a link to the anchor name, with the name
+// percent-encoded so that non-ASCII names work in all browsers.
+func anchorHref(name string) string {
+	return "#" + url.PathEscape(name)
+}
+
+// githubName returns the anchor name GitHub gives a heading with the given
+// title. Unlike name, GitHub numbers repeated slugs without checking whether
+// the result is used already.
+func (a *anchorNamer) githubName(title string) string {
+	slug := githubSlug(title)
+	if a.slug != nil {
+		slug = a.slug(title)
+	}
+	n := a.used[slug]
+	a.used[slug]++
+	if n > 0 {
+		slug = fmt.Sprintf("%s-%d", slug, n)
+	}
+	return userContentPrefix + slug
+}
+
+// githubPunctuationRE matches the characters GitHub removes from heading slugs.
+var githubPunctuationRE = regexp.MustCompile(`[^\p{L}\p{M}\p{N}\p{Pc}\- ]`)
+
+// githubSlug returns title in lowercase, without punctuation and with each
+// space replaced by a dash, like GitHub's heading slugs.
+func githubSlug(title string) string {
+	slug := githubPunctuationRE.ReplaceAllString(strings.ToLower(title), "")
+	return strings.Replace(slug, " ", "-", -1)
+}
+
+// AnchorMap returns a map from each heading anchor name in text to the
+// plain text title of its heading. The anchor names are the same as those
+// in the output of Markdown with the same options, including suffixes added
+// to repeated names.
+func AnchorMap(text []byte, opts ...Option) map[string]string {
+	m := make(map[string]string)
+	for _, e := range TOC(text, opts...) {
+		m[e.Anchor] = e.Title
+	}
+	return m
+}
@@ -0,0 +1,133 @@
+package github_flavored_markdown_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestAnchorMap(t *testing.T) {
+	text := []byte("# Example\n\n## Example\n\n## Example 1\n\n### Example\n\n## Use `go get`\n")
+	want := map[string]string{
+		"example":     "Example",
+		"example-1":   "Example",
+		"example-1-1": "Example 1",
+		"example-2":   "Example",
+		"use-go-get":  "Use go get",
+	}
+
+	got := github_flavored_markdown.AnchorMap(text)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot %v\nwant %v", got, want)
+	}
+
+	// The same anchors must be rendered.
+	rendered := string(github_flavored_markdown.Markdown(text))
+	for anchor := range want {
+		if !strings.Contains(rendered, `href="#`+anchor+`"`) {
+			t.Errorf("rendered output doesn't contain anchor %q:\n%s", anchor, rendered)
+		}
+	}
+}
+
+func TestDuplicateAnchors(t *testing.T) {
+	text := []byte("## Example\n\n## Example\n\n## Example\n")
+	want := []string{`name="example"`, `name="example-1"`, `name="example-2"`}
+
+	// Each render numbers repeated anchors from the start.
+	for i := 0; i < 2; i++ {
+		rendered := string(github_flavored_markdown.Markdown(text))
+		for _, name := range want {
+			j := strings.Index(rendered, name)
+			if j < 0 {
+				t.Fatalf("rendered output doesn't contain %s, or not in order:\n%s", name, rendered)
+			}
+			rendered = rendered[j+len(name):]
+		}
+	}
+}
+
+func TestGitHubAnchors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "## What's new? (v2.0)",
+			want: `<h2><a name="user-content-whats-new-v20" class="anchor" href="#whats-new-v20" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>What&#39;s new? (v2.0)</h2>` + "\n",
+		},
+		{
+			// Spaces are not collapsed, and underscores are kept.
+			text: "## a - b_c",
+			want: `<h2><a name="user-content-a---b_c" class="anchor" href="#a---b_c" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>a - b_c</h2>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithGitHubAnchors())); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+
+	// Repeated slugs are numbered like on GitHub.
+	text := []byte("# Example\n\n# Example\n\n# Example 1\n")
+	want := map[string]string{
+		"user-content-example":   "Example",
+		"user-content-example-1": "Example 1",
+	}
+	if got := github_flavored_markdown.AnchorMap(text, github_flavored_markdown.WithGitHubAnchors()); !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot %v\nwant %v", got, want)
+	}
+}
+
+func TestUnicodeAnchors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "## 安装说明",
+			want: `<h2><a name="安装说明" class="anchor" href="#%E5%AE%89%E8%A3%85%E8%AF%B4%E6%98%8E" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>安装说明</h2>` + "\n",
+		},
+		{
+			text: "## Привет, мир",
+			want: `<h2><a name="привет-мир" class="anchor" href="#%D0%BF%D1%80%D0%B8%D0%B2%D0%B5%D1%82-%D0%BC%D0%B8%D1%80" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Привет, мир</h2>` + "\n",
+		},
+		{
+			// Combining marks are part of words.
+			text: "## हिन्दी पाठ",
+			want: `<h2><a name="हिन्दी-पाठ" class="anchor" href="#%E0%A4%B9%E0%A4%BF%E0%A4%A8%E0%A5%8D%E0%A4%A6%E0%A5%80-%E0%A4%AA%E0%A4%BE%E0%A4%A0" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>हिन्दी पाठ</h2>` + "\n",
+		},
+		{
+			text: "## 🎉",
+			want: `<h2><a name="heading" class="anchor" href="#heading" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>🎉</h2>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestWithSlug(t *testing.T) {
+	underscores := func(title string) string {
+		return strings.ToLower(strings.Replace(title, " ", "_", -1))
+	}
+	text := []byte("## Getting Started\n\n## Getting Started\n")
+	want := `<h2><a name="getting_started" class="anchor" href="#getting_started" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Getting Started</h2>` + "\n\n" +
+		`<h2><a name="getting_started-1" class="anchor" href="#getting_started-1" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Getting Started</h2>` + "\n"
+
+	if got := string(github_flavored_markdown.Markdown(text, github_flavored_markdown.WithSlug(underscores))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	// The TOC uses the same names.
+	toc := github_flavored_markdown.TOC(text, github_flavored_markdown.WithSlug(underscores))
+	if len(toc) != 2 || toc[0].Anchor != "getting_started" || toc[1].Anchor != "getting_started-1" {
+		t.Errorf("got %+v", toc)
+	}
+}
@@ -0,0 +1,142 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// Extended autolinks, as specified by the GFM spec, that blackfriday's
+// Autolink extension doesn't handle: links starting with "www." and
+// email addresses. Both must follow the start of text, whitespace or one of
+// the delimiters the spec allows.
+var (
+	wwwAutolinkRE   = regexp.MustCompile(`(?:^|[\s*_~(])(www\.[^\s<]*)`)
+	emailAutolinkRE = regexp.MustCompile(`(?:^|[^\w.+\-@/])([\w.+\-]+@[\w\-]+(?:\.[\w\-]+)+)`)
+)
+
+// entityEndRE matches an entity reference at the end of an autolink,
+// which isn't part of it.
+var entityEndRE = regexp.MustCompile(`&[A-Za-z0-9]+;$`)
+
+// autolinks links the extended autolinks in the text of doc.
+// Text in links is skipped.
+func autolinks(doc *bf.Node) {
+	replaceUnlinkedText(doc, func(text []byte) []*bf.Node {
+		if !bytes.Contains(text, []byte("www.")) && !bytes.Contains(text, []byte("@")) {
+			return nil
+		}
+		return linkAutolinks(text)
+	})
+}
+
+// linkAutolinks returns the nodes that text with extended autolinks
+// replaced by links consists of, or nil if it has none.
+func linkAutolinks(text []byte) []*bf.Node {
+	type match struct {
+		start, end int
+		scheme     string
+	}
+	var matches []match
+	for _, loc := range wwwAutolinkRE.FindAllSubmatchIndex(text, -1) {
+		matches = append(matches, match{loc[2], loc[3], "http://"})
+	}
+	for _, loc := range emailAutolinkRE.FindAllSubmatchIndex(text, -1) {
+		matches = append(matches, match{loc[2], loc[3], "mailto:"})
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	var nodes []*bf.Node
+	var prev int
+	for _, m := range matches {
+		if m.start < prev {
+			continue
+		}
+		link := text[m.start:m.end]
+		if m.scheme == "mailto:" {
+			link = bytes.TrimRight(link, ".")
+		} else {
+			link = trimAutolink(link)
+		}
+		if !validAutolink(link, m.scheme) {
+			continue
+		}
+		if m.start > prev {
+			nodes = append(nodes, newText(text[prev:m.start]))
+		}
+		nodes = append(nodes, newLink(append([]byte(m.scheme), link...), link))
+		prev = m.start + len(link)
+	}
+	if nodes == nil {
+		return nil
+	}
+	if prev < len(text) {
+		nodes = append(nodes, newText(text[prev:]))
+	}
+	return nodes
+}
+
+// trimAutolink removes the trailing punctuation, unmatched closing
+// parentheses and entity references that the GFM spec excludes from
+// the end of an extended autolink.
+func trimAutolink(link []byte) []byte {
+	for len(link) > 0 {
+		switch link[len(link)-1] {
+		case '?', '!', '.', ',', ':', '*', '_', '~':
+			link = link[:len(link)-1]
+		case ')':
+			if bytes.Count(link, []byte(")")) <= bytes.Count(link, []byte("(")) {
+				return link
+			}
+			link = link[:len(link)-1]
+		case ';':
+			loc := entityEndRE.FindIndex(link)
+			if loc == nil {
+				return link
+			}
+			link = link[:loc[0]]
+		default:
+			return link
+		}
+	}
+	return link
+}
+
+// validAutolink reports whether link has a valid domain: segments of
+// alphanumerics, underscores and hyphens separated by periods, with at least
+// one period, and no underscores in the last two segments. For email
+// addresses, the domain may not end in a hyphen or underscore.
+func validAutolink(link []byte, scheme string) bool {
+	var domain []byte
+	if scheme == "mailto:" {
+		domain = link[bytes.IndexByte(link, '@')+1:]
+		if c := domain[len(domain)-1]; c == '-' || c == '_' {
+			return false
+		}
+	} else {
+		domain = link
+		if i := bytes.IndexAny(domain, "/?#"); i >= 0 {
+			domain = domain[:i]
+		}
+	}
+	segments := bytes.Split(domain, []byte("."))
+	if len(segments) < 2 {
+		return false
+	}
+	for i, s := range segments {
+		if len(s) == 0 {
+			return false
+		}
+		for _, c := range s {
+			if !isIdentByte(c) && c != '-' {
+				return false
+			}
+		}
+		if i >= len(segments)-2 && bytes.IndexByte(s, '_') >= 0 {
+			return false
+		}
+	}
+	return true
+}
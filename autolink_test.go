@@ -0,0 +1,80 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+// Cases from the GFM spec's autolinks extension section.
+func TestExtendedAutolinks(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "www.commonmark.org",
+			want: `<p><a href="http://www.commonmark.org" rel="nofollow">www.commonmark.org</a></p>` + "\n",
+		},
+		{
+			text: "Visit www.commonmark.org/help for more information.",
+			want: `<p>Visit <a href="http://www.commonmark.org/help" rel="nofollow">www.commonmark.org/help</a> for more information.</p>` + "\n",
+		},
+		{
+			text: "Visit www.commonmark.org/a.b.",
+			want: `<p>Visit <a href="http://www.commonmark.org/a.b" rel="nofollow">www.commonmark.org/a.b</a>.</p>` + "\n",
+		},
+		{
+			text: "www.google.com/search?q=Markup+(business)))",
+			want: `<p><a href="http://www.google.com/search?q=Markup+(business)" rel="nofollow">www.google.com/search?q=Markup+(business)</a>))</p>` + "\n",
+		},
+		{
+			text: "(www.google.com/search?q=Markup+(business))",
+			want: `<p>(<a href="http://www.google.com/search?q=Markup+(business)" rel="nofollow">www.google.com/search?q=Markup+(business)</a>)</p>` + "\n",
+		},
+		{
+			text: "www.google.com/search?q=(business))+ok",
+			want: `<p><a href="http://www.google.com/search?q=(business))+ok" rel="nofollow">www.google.com/search?q=(business))+ok</a></p>` + "\n",
+		},
+		{
+			text: "www.google.com/search?q=commonmark&hl=en",
+			want: `<p><a href="http://www.google.com/search?q=commonmark&amp;hl=en" rel="nofollow">www.google.com/search?q=commonmark&amp;hl=en</a></p>` + "\n",
+		},
+		{
+			text: "www.google.com/search?q=commonmark&hl;",
+			want: `<p><a href="http://www.google.com/search?q=commonmark" rel="nofollow">www.google.com/search?q=commonmark</a>&amp;hl;</p>` + "\n",
+		},
+		{
+			// Underscores aren't allowed in the last two domain segments.
+			text: "wwwx.com www.a_b.com www.a_b.c.d",
+			want: `<p>wwwx.com www.a_b.com <a href="http://www.a_b.c.d" rel="nofollow">www.a_b.c.d</a></p>` + "\n",
+		},
+		{
+			text: "foo@bar.baz",
+			want: `<p><a href="mailto:foo@bar.baz" rel="nofollow">foo@bar.baz</a></p>` + "\n",
+		},
+		{
+			text: "hello@mail+xyz.example isn't valid, but hello+xyz@mail.example is.",
+			want: `<p>hello@mail+xyz.example isn&#39;t valid, but <a href="mailto:hello+xyz@mail.example" rel="nofollow">hello+xyz@mail.example</a> is.</p>` + "\n",
+		},
+		{
+			text: "a.b-c_d@a.b.",
+			want: `<p><a href="mailto:a.b-c_d@a.b" rel="nofollow">a.b-c_d@a.b</a>.</p>` + "\n",
+		},
+		{
+			text: "a.b-c_d@a.b-",
+			want: "<p>a.b-c_d@a.b-</p>\n",
+		},
+		{
+			// Links aren't nested.
+			text: "[www.example.com](https://example.org/) `www.example.com`",
+			want: `<p><a href="https://example.org/" rel="nofollow">www.example.com</a> <code>www.example.com</code></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
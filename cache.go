@@ -0,0 +1,178 @@
+package github_flavored_markdown
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// Cache stores rendered output by a key derived from the input and the
+// options it was rendered with. Implementations must be safe for concurrent
+// use. See Options.Cache.
+type Cache interface {
+	// Get returns the output stored for key, if any.
+	Get(key string) ([]byte, bool)
+	// Add stores output for key. It must not modify output.
+	Add(key string, output []byte)
+}
+
+// LRUCache is a Cache that holds a limited number of renders, evicting
+// the least recently used ones first. It is safe for concurrent use.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List               // Of *lruEntry, most recently used first.
+	entries    map[string]*list.Element // By key.
+}
+
+type lruEntry struct {
+	key    string
+	output []byte
+}
+
+// NewLRUCache returns an LRUCache that holds up to maxEntries renders.
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the output stored for key, if any.
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*lruEntry).output, true
+}
+
+// Add stores output for key, evicting the least recently used render
+// if the cache is full.
+func (c *LRUCache) Add(key string, output []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*lruEntry).output = output
+		return
+	}
+	c.entries[key] = c.ll.PushFront(&lruEntry{key: key, output: output})
+	if c.ll.Len() > c.maxEntries {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.entries, e.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of renders in the cache.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// cacheKey returns the cache key for rendering text with opts, a hash of
+// both. It reports false if the output can't be cached, because opts hold
+// functions, which can't be compared.
+func cacheKey(text []byte, opts Options) (string, bool) {
+	// Neither affects the output of a complete render.
+	opts.Cache, opts.Timeout = nil, 0
+
+	h := sha256.New()
+	h.Write(text)
+	if !hashValue(h, reflect.ValueOf(opts), make(map[visit]bool)) {
+		return "", false
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// policyType is the type of Options.Policy.
+var policyType = reflect.TypeOf((*bluemonday.Policy)(nil))
+
+// A visit is a pointer that hashValue is hashing the value of.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// hashValue writes v to h, so that equal values are written the same.
+// Pointers are followed, except for policies, which can't be compared and
+// are written by address. It reports false if v holds a function, a
+// channel or a cycle of pointers. seen holds the pointers being followed.
+func hashValue(h hash.Hash, v reflect.Value, seen map[visit]bool) bool {
+	switch v.Kind() {
+	case reflect.Func:
+		if !v.IsNil() {
+			return false
+		}
+		fmt.Fprint(h, "nil;")
+	case reflect.Ptr:
+		switch {
+		case v.IsNil():
+			fmt.Fprint(h, "nil;")
+			return true
+		case v.Type() == policyType:
+			fmt.Fprintf(h, "%x;", v.Pointer())
+			return true
+		}
+		p := visit{v.Pointer(), v.Type()}
+		if seen[p] {
+			return false
+		}
+		seen[p] = true
+		defer delete(seen, p)
+		fmt.Fprint(h, "&")
+		return hashValue(h, v.Elem(), seen)
+	case reflect.Chan, reflect.UnsafePointer:
+		return false
+	case reflect.Interface:
+		if v.IsNil() {
+			fmt.Fprint(h, "nil;")
+			return true
+		}
+		fmt.Fprintf(h, "%s:", v.Elem().Type())
+		return hashValue(h, v.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hashValue(h, v.Field(i), seen) {
+				return false
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		fmt.Fprintf(h, "%d[", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if !hashValue(h, v.Index(i), seen) {
+				return false
+			}
+		}
+		fmt.Fprint(h, "]")
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		fmt.Fprintf(h, "%d{", len(keys))
+		for _, k := range keys {
+			if !hashValue(h, k, seen) || !hashValue(h, v.MapIndex(k), seen) {
+				return false
+			}
+		}
+		fmt.Fprint(h, "}")
+	case reflect.String:
+		fmt.Fprintf(h, "%q;", v.String())
+	default:
+		// Booleans and numbers.
+		fmt.Fprintf(h, "%v;", v)
+	}
+	return true
+}
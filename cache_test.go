@@ -0,0 +1,110 @@
+package github_flavored_markdown_test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := github_flavored_markdown.NewLRUCache(2)
+	c.Add("a", []byte("A"))
+	c.Add("b", []byte("B"))
+	c.Get("a")
+	c.Add("c", []byte("C"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("least recently used entry not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("entry %q evicted", key)
+		}
+	}
+	if got, want := c.Len(), 2; got != want {
+		t.Errorf("got length %d, want %d", got, want)
+	}
+}
+
+// countingCache counts the hits and misses of a Cache.
+type countingCache struct {
+	github_flavored_markdown.Cache
+	hits, misses int
+}
+
+func (c *countingCache) Get(key string) ([]byte, bool) {
+	out, ok := c.Cache.Get(key)
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return out, ok
+}
+
+func TestWithCache(t *testing.T) {
+	text := []byte("Hello **world** :smile:.\n")
+	c := &countingCache{Cache: github_flavored_markdown.NewLRUCache(10)}
+
+	want := github_flavored_markdown.Markdown(text)
+	for i := 0; i < 2; i++ {
+		got := github_flavored_markdown.Markdown(text, github_flavored_markdown.WithCache(c))
+		if !bytes.Equal(got, want) {
+			t.Errorf("\ngot %q\nwant %q", got, want)
+		}
+		// Modifying the output doesn't modify the cached output.
+		got[0] = 'X'
+	}
+	if c.hits != 1 || c.misses != 1 {
+		t.Errorf("got %d hits, %d misses, want 1 hit, 1 miss", c.hits, c.misses)
+	}
+
+	// Different options are cached separately.
+	want = github_flavored_markdown.Markdown(text, github_flavored_markdown.WithEmoji())
+	got := github_flavored_markdown.Markdown(text, github_flavored_markdown.WithCache(c), github_flavored_markdown.WithEmoji())
+	if !bytes.Equal(got, want) {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+	if c.hits != 1 || c.misses != 2 {
+		t.Errorf("got %d hits, %d misses, want 1 hit, 2 misses", c.hits, c.misses)
+	}
+
+	// Options with functions aren't cached.
+	h := github_flavored_markdown.HighlighterFunc(func(src []byte, lang string) ([]byte, bool) { return nil, false })
+	github_flavored_markdown.Markdown(text, github_flavored_markdown.WithCache(c), github_flavored_markdown.WithHighlighter(h))
+	if c.hits != 1 || c.misses != 2 {
+		t.Errorf("got %d hits, %d misses, want 1 hit, 2 misses", c.hits, c.misses)
+	}
+}
+
+func TestWithCachePointers(t *testing.T) {
+	// Options that point to different values are cached separately, even
+	// when the pointers are at the same address.
+	text := []byte("See #1 and ![a](http://img.example.net/a.png).\n")
+	c := &countingCache{Cache: github_flavored_markdown.NewLRUCache(100)}
+	for i := 0; i < 20; i++ {
+		opts := []github_flavored_markdown.Option{
+			github_flavored_markdown.WithReferences(github_flavored_markdown.References{Repository: fmt.Sprintf("owner/repo%d", i%5)}),
+			github_flavored_markdown.WithImageProxy(github_flavored_markdown.ImageProxy{URL: fmt.Sprintf("https://camo%d.example.com/{hexurl}", i%5)}),
+		}
+		want := github_flavored_markdown.Markdown(text, opts...)
+		got := github_flavored_markdown.Markdown(text, append(opts, github_flavored_markdown.WithCache(c))...)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%d:\ngot %q\nwant %q", i, got, want)
+		}
+		runtime.GC()
+	}
+	if c.hits != 15 || c.misses != 5 {
+		t.Errorf("got %d hits, %d misses, want 15 hits, 5 misses", c.hits, c.misses)
+	}
+
+	// Functions behind pointers aren't cached.
+	refs := github_flavored_markdown.References{Resolve: func(repo string, number int) (string, bool) { return "", false }}
+	github_flavored_markdown.Markdown(text, github_flavored_markdown.WithCache(c), github_flavored_markdown.WithReferences(refs))
+	if c.hits != 15 || c.misses != 5 {
+		t.Errorf("got %d hits, %d misses, want 15 hits, 5 misses", c.hits, c.misses)
+	}
+}
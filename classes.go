@@ -0,0 +1,89 @@
+package github_flavored_markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ClassScheme is a scheme of class names for highlighted code, which
+// Options.CodeClasses renames the classes of the built-in highlighters to.
+type ClassScheme struct {
+	// Classes maps the classes of the built-in highlighters, such as "k"
+	// for keywords, to the space-separated classes to use instead. Classes
+	// that aren't in the map are kept, and those mapped to "" are removed.
+	Classes map[string]string
+
+	// Block, if not empty, is added to the classes of the <div> elements
+	// of highlighted code blocks.
+	Block string
+
+	// Language adds a language-<lang> class to the <pre> elements of
+	// highlighted code blocks.
+	Language bool
+}
+
+var (
+	// HLJSClasses are the classes of highlight.js, for its stylesheets.
+	HLJSClasses = &ClassScheme{
+		Classes: map[string]string{
+			gfmHTMLConfig.String:        "hljs-string",
+			gfmHTMLConfig.Keyword:       "hljs-keyword",
+			gfmHTMLConfig.Comment:       "hljs-comment",
+			gfmHTMLConfig.Plaintext:     "", // Also Type.
+			gfmHTMLConfig.Literal:       "hljs-literal",
+			gfmHTMLConfig.Punctuation:   "hljs-punctuation",
+			gfmHTMLConfig.Tag:           "hljs-tag",
+			gfmHTMLConfig.HTMLTag:       "hljs-name",
+			gfmHTMLConfig.HTMLAttrName:  "hljs-attr",
+			gfmHTMLConfig.HTMLAttrValue: "hljs-string",
+			gfmHTMLConfig.Decimal:       "hljs-number",
+			"gd":                        "hljs-deletion",
+			"gi":                        "hljs-addition",
+		},
+		Block:    "hljs",
+		Language: true,
+	}
+
+	// PrismClasses are the classes of Prism, for its stylesheets.
+	PrismClasses = &ClassScheme{
+		Classes: map[string]string{
+			gfmHTMLConfig.String:        "token string",
+			gfmHTMLConfig.Keyword:       "token keyword",
+			gfmHTMLConfig.Comment:       "token comment",
+			gfmHTMLConfig.Plaintext:     "", // Also Type.
+			gfmHTMLConfig.Literal:       "token constant",
+			gfmHTMLConfig.Punctuation:   "token punctuation",
+			gfmHTMLConfig.Tag:           "token tag",
+			gfmHTMLConfig.HTMLTag:       "token tag",
+			gfmHTMLConfig.HTMLAttrName:  "token attr-name",
+			gfmHTMLConfig.HTMLAttrValue: "token attr-value",
+			gfmHTMLConfig.Decimal:       "token number",
+			"gd":                        "token deleted",
+			"gi":                        "token inserted",
+		},
+		Language: true,
+	}
+)
+
+// spanClassRE matches the start tags of the spans of highlighted code.
+var spanClassRE = regexp.MustCompile(`<span class="([^"]*)">`)
+
+// rename returns code, highlighted by the built-in highlighters, with the
+// classes of its spans renamed according to s.
+func (s *ClassScheme) rename(code []byte) []byte {
+	return spanClassRE.ReplaceAllFunc(code, func(tag []byte) []byte {
+		var classes []string
+		for _, class := range strings.Fields(string(spanClassRE.FindSubmatch(tag)[1])) {
+			if c, ok := s.Classes[class]; ok {
+				class = c
+			}
+			if class != "" {
+				classes = append(classes, class)
+			}
+		}
+		if len(classes) == 0 {
+			return []byte("<span>")
+		}
+		return []byte(`<span class="` + strings.Join(classes, " ") + `">`)
+	})
+}
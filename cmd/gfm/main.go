@@ -0,0 +1,163 @@
+// gfm renders GitHub Flavored Markdown to HTML.
+//
+// Usage:
+//
+//	gfm [flags] [file]
+//	gfm serve [flags] [file or directory]
+//
+// It reads Markdown from file, or from standard input if no file is given,
+// and writes the rendered HTML to standard output. With -page, it writes
+// a complete HTML page with the CSS of the package embedded, which can be
+// opened in a browser as is. The other flags correspond to the rendering options
+// of the github_flavored_markdown package.
+//
+// The serve command starts a web server that previews the Markdown files of
+// a directory, or of the directory of a file, as they are rendered by gfm.
+// It watches the files for changes, and reloads the pages open in a browser
+// when they change.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "gfm:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs gfm with the command line arguments args.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) > 0 && args[0] == "serve" {
+		return serve(args[1:], stderr)
+	}
+
+	fs := flag.NewFlagSet("gfm", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gfm [flags] [file]\n       gfm serve [flags] [file or directory]")
+		fs.PrintDefaults()
+	}
+	var (
+		page  = fs.Bool("page", false, "write a complete HTML page with embedded CSS")
+		title = fs.String("title", "", "title of the page written with -page (default the first heading, or the file name)")
+		flags optionFlags
+	)
+	flags.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errors.New("too many arguments")
+	}
+	opts := flags.options()
+
+	name := "stdin"
+	r := stdin
+	if fs.NArg() == 1 {
+		name = fs.Arg(0)
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	if n := opts.MaxInputSize; n >= 0 {
+		if n == 0 {
+			n = github_flavored_markdown.DefaultMaxInputSize
+		}
+		// Read one byte more, so that MarkdownTo reports input that's too large.
+		r = io.LimitReader(r, n+1)
+	}
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(stdout)
+	if *page {
+		if *title == "" {
+			var ok bool
+			if *title, ok = github_flavored_markdown.Title(text, github_flavored_markdown.WithOptions(opts)); !ok {
+				*title = filepath.Base(name)
+			}
+		}
+		var body bytes.Buffer
+		if err := github_flavored_markdown.MarkdownTo(&body, text, github_flavored_markdown.WithOptions(opts)); err != nil {
+			return err
+		}
+		w.Write(github_flavored_markdown.Page(*title, body.Bytes()))
+	} else if err := github_flavored_markdown.MarkdownTo(w, text, github_flavored_markdown.WithOptions(opts)); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// optionFlags are the flags for the rendering options.
+type optionFlags struct {
+	opts    github_flavored_markdown.Options
+	comment bool
+	schemes string
+	origins string
+}
+
+// register defines the flags in fs.
+func (f *optionFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&f.comment, "comment", false, "render like GitHub renders comments, with line breaks kept")
+	fs.StringVar(&f.schemes, "url-schemes", "", "comma-separated `list` of additional URL schemes to allow in links")
+	fs.StringVar(&f.origins, "iframe-origins", "", "comma-separated `list` of origins to allow iframes from")
+	fs.BoolVar(&f.opts.SkipSanitization, "unsafe", false, "don't sanitize the output; only use with trusted input")
+	fs.BoolVar(&f.opts.Footnotes, "footnotes", false, "render footnotes")
+	fs.BoolVar(&f.opts.Emoji, "emoji", false, "render emoji shortcodes such as :smile:")
+	fs.BoolVar(&f.opts.Math, "math", false, "render math")
+	fs.BoolVar(&f.opts.Mermaid, "mermaid", false, "render Mermaid diagrams")
+	fs.BoolVar(&f.opts.FrontMatter, "front-matter", false, "strip YAML or TOML front matter")
+	fs.BoolVar(&f.opts.TOC, "toc", false, "write a table of contents before the document")
+	fs.BoolVar(&f.opts.DefinitionLists, "definition-lists", false, "render definition lists")
+	fs.BoolVar(&f.opts.Spoilers, "spoilers", false, "render ||spoiler|| text")
+	fs.BoolVar(&f.opts.RichTableCells, "rich-table-cells", false, "render lists and line breaks in table cells")
+	fs.BoolVar(&f.opts.TagFilter, "tag-filter", false, "filter raw HTML tags like GitHub does")
+	fs.BoolVar(&f.opts.GitHubAnchors, "github-anchors", false, "name heading anchors like GitHub does")
+	fs.BoolVar(&f.opts.HeadingIDs, "heading-ids", false, "give headings id attributes")
+	fs.BoolVar(&f.opts.NoHeadingAnchors, "no-heading-anchors", false, "don't render heading anchor links")
+	fs.BoolVar(&f.opts.LineNumbers, "line-numbers", false, "number the lines of code blocks")
+	fs.BoolVar(&f.opts.CodeLangBadge, "lang-badge", false, "show the language of code blocks")
+	fs.BoolVar(&f.opts.Smartypants, "smartypants", false, "use typographic quotes and dashes")
+	fs.BoolVar(&f.opts.DirAuto, "dir-auto", false, "add dir=\"auto\" to block elements")
+	fs.StringVar(&f.opts.DefaultCodeLang, "default-lang", "", "language of code blocks that don't specify one")
+	fs.StringVar(&f.opts.BaseURL, "base-url", "", "resolve relative links and images against `URL`")
+	fs.Int64Var(&f.opts.MaxInputSize, "max-input-size", 0, "maximum input size in bytes (default 400 KiB, negative for no limit)")
+	fs.DurationVar(&f.opts.Timeout, "timeout", 0, "stop rendering after `duration`")
+}
+
+// options returns the options set by the flags.
+func (f *optionFlags) options() github_flavored_markdown.Options {
+	opts := f.opts
+	if f.comment {
+		opts.Mode = github_flavored_markdown.CommentMode
+	}
+	if f.schemes != "" {
+		opts.URLSchemes = strings.Split(f.schemes, ",")
+	}
+	if f.origins != "" {
+		opts.IframeOrigins = strings.Split(f.origins, ",")
+	}
+	return opts
+}
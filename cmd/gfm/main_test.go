@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(file, []byte("Hello <b>world</b>.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args  []string
+		stdin string
+		want  []string
+	}{
+		{
+			stdin: "# Title\n\nHello <script>alert();</script>:smile:\n",
+			want:  []string{`<h1><a name="title" class="anchor" href="#title" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Title</h1>`, "<p>Hello :smile:</p>\n"},
+		},
+		{
+			args:  []string{"-emoji"},
+			stdin: "Hello :smile:\n",
+			want:  []string{"<p>Hello 😄</p>\n"},
+		},
+		{
+			args:  []string{"-comment"},
+			stdin: "a\nb\n",
+			want:  []string{"<p>a<br>\nb</p>\n"},
+		},
+		{
+			args: []string{"-unsafe", file},
+			want: []string{"<p>Hello <b>world</b>.</p>\n"},
+		},
+		{
+			args:  []string{"-page"},
+			stdin: "# A & B\n",
+			want:  []string{"<!DOCTYPE html>", "<title>A &amp; B</title>", ".markdown-body", "</article></body></html>\n"},
+		},
+		{
+			args: []string{"-page", file},
+			want: []string{"<title>doc.md</title>"},
+		},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		if err := run(test.args, strings.NewReader(test.stdin), &stdout, &stderr); err != nil {
+			t.Errorf("%q: %v", test.args, err)
+			continue
+		}
+		for _, want := range test.want {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("%q: output doesn't contain %q:\n%s", test.args, want, stdout.String())
+			}
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-no-such-flag"},
+		{"a.md", "b.md"},
+		{filepath.Join(t.TempDir(), "missing.md")},
+		{"-max-input-size", "3"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if err := run(args, strings.NewReader("text"), &stdout, &stderr); err == nil {
+			t.Errorf("%q: no error", args)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+// eventsPath is the path of the server-sent events that tell pages to reload.
+const eventsPath = "/.gfm/events"
+
+// reloadScript is added to the HTML pages served by serve.
+const reloadScript = `<script>new EventSource("` + eventsPath + `").onmessage = function() { location.reload(); };</script>
+`
+
+// serve runs the serve command with the command line arguments args.
+func serve(args []string, stderr io.Writer) error {
+	fset := flag.NewFlagSet("gfm serve", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	fset.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gfm serve [flags] [file or directory]")
+		fset.PrintDefaults()
+	}
+	var (
+		addr  = fset.String("http", "localhost:8080", "listen for HTTP connections on `address`")
+		poll  = fset.Duration("poll", 500*time.Millisecond, "check for changed files every `interval`")
+		flags optionFlags
+	)
+	flags.register(fset)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() > 1 {
+		fset.Usage()
+		return errors.New("too many arguments")
+	}
+	if *poll <= 0 {
+		fset.Usage()
+		return errors.New("-poll interval must be positive")
+	}
+
+	dir, page := ".", "/"
+	if fset.NArg() == 1 {
+		dir = fset.Arg(0)
+		fi, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			dir, page = filepath.Dir(dir), "/"+filepath.Base(dir)
+		}
+	}
+
+	p := newPreview(os.DirFS(dir), github_flavored_markdown.WithOptions(flags.options()))
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.watch(ctx, *poll)
+	fmt.Fprintf(stderr, "Serving %s at http://%s%s\n", dir, ln.Addr(), page)
+	return http.Serve(ln, p)
+}
+
+// preview serves the files of fsys like github_flavored_markdown.Handler,
+// and reloads the pages that are open in a browser when the files change.
+type preview struct {
+	fsys  fs.FS
+	pages http.Handler
+
+	mu      sync.Mutex
+	clients map[chan struct{}]bool // Each receives a value when the files change.
+}
+
+func newPreview(fsys fs.FS, opts ...github_flavored_markdown.Option) *preview {
+	return &preview{
+		fsys:    fsys,
+		pages:   github_flavored_markdown.Handler(fsys, opts...),
+		clients: make(map[chan struct{}]bool),
+	}
+}
+
+func (p *preview) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == eventsPath {
+		p.events(w, req)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	rw := &reloadWriter{ResponseWriter: w}
+	p.pages.ServeHTTP(rw, req)
+	if rw.reload {
+		io.WriteString(w, reloadScript)
+	}
+}
+
+// events streams an event to req each time the files change, until the
+// request is canceled.
+func (p *preview) events(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	c := make(chan struct{}, 1)
+	p.mu.Lock()
+	p.clients[c] = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.clients, c)
+		p.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-c:
+			io.WriteString(w, "data: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// reload tells the pages that are open to reload.
+func (p *preview) reload() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for c := range p.clients {
+		select {
+		case c <- struct{}{}:
+		default:
+			// A reload is already pending.
+		}
+	}
+}
+
+// watch checks the files of p for changes every interval, and reloads the
+// pages that are open when they change, until ctx is done.
+func (p *preview) watch(ctx context.Context, interval time.Duration) {
+	last := p.snapshot()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if s := p.snapshot(); !s.equal(last) {
+			last = s
+			p.reload()
+		}
+	}
+}
+
+// fileState is the state of a file that is compared to detect changes.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshot is the state of each file of a file system, by name.
+type snapshot map[string]fileState
+
+func (s snapshot) equal(t snapshot) bool {
+	if len(s) != len(t) {
+		return false
+	}
+	for name, a := range s {
+		if b, ok := t[name]; !ok || b.size != a.size || !b.modTime.Equal(a.modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// snapshot returns the state of the files of p. Hidden files and
+// directories, such as .git, are skipped.
+func (p *preview) snapshot() snapshot {
+	s := make(snapshot)
+	fs.WalkDir(p.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if name != "." && strings.HasPrefix(path.Base(name), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil {
+			s[name] = fileState{size: fi.Size(), modTime: fi.ModTime()}
+		}
+		return nil
+	})
+	return s
+}
+
+// reloadWriter is an http.ResponseWriter that records whether the response
+// is an HTML page, which reloadScript is added to.
+type reloadWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	reload      bool
+}
+
+func (w *reloadWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code == http.StatusOK && strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		w.Header().Del("Content-Length")
+		w.reload = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *reloadWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreview(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(file, []byte("# Hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := newPreview(os.DirFS(dir))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.watch(ctx, 10*time.Millisecond)
+	ts := httptest.NewServer(p)
+	defer ts.Close()
+
+	// Pages have the reload script, and other files are served as is.
+	if got := get(t, ts.URL+"/"); !strings.Contains(got, "Hello</h1>") || !strings.HasSuffix(got, reloadScript) {
+		t.Errorf("page doesn't end with the reload script:\n%s", got)
+	}
+	if got, want := get(t, ts.URL+"/notes.txt"), "notes"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	resp, err := http.Get(ts.URL + eventsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.Header.Get("Content-Type"), "text/event-stream"; got != want {
+		t.Errorf("got Content-Type %q, want %q", got, want)
+	}
+	events := make(chan string)
+	go func() {
+		s := bufio.NewScanner(resp.Body)
+		for s.Scan() {
+			if s.Text() != "" {
+				events <- s.Text()
+			}
+		}
+	}()
+
+	// Wait for the events request to be handled before changing the file.
+	for {
+		p.mu.Lock()
+		n := len(p.clients)
+		p.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := os.WriteFile(file, []byte("# Hello, world\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-events:
+		if want := "data: reload"; got != want {
+			t.Errorf("got event %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload event after the file changed")
+	}
+	if got := get(t, ts.URL+"/"); !strings.Contains(got, "Hello, world</h1>") {
+		t.Errorf("page isn't rendered again after the file changed:\n%s", got)
+	}
+}
+
+func get(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestServeErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"a", "b"},
+		{"-poll", "0"},
+		{"-poll", "-1s"},
+	} {
+		if err := serve(args, io.Discard); err == nil {
+			t.Errorf("%q: no error", args)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+//go:build js && wasm
+
+// gfmwasm is a WebAssembly build of the renderer for browsers, so that
+// editors can preview Markdown exactly as it's rendered on the server.
+//
+// It defines a global gfm object with a function that renders Markdown:
+//
+//	gfm.render(markdown[, options]) -> html
+//
+// options is an optional object whose properties enable the options named
+// like the flags of the gfm command, in camel case, such as
+// {footnotes: true, lineNumbers: true}. render returns null if markdown
+// isn't a string.
+//
+// Build it and copy the JavaScript support file of Go with:
+//
+//	GOOS=js GOARCH=wasm go build -o gfm.wasm github.com/shurcooL/github_flavored_markdown/cmd/gfmwasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// Before Go 1.24, wasm_exec.js is in misc/wasm instead of lib/wasm.
+//
+// Then load it in a page:
+//
+//	<script src="wasm_exec.js"></script>
+//	<script>
+//	const go = new Go();
+//	WebAssembly.instantiateStreaming(fetch("gfm.wasm"), go.importObject).then((result) => {
+//		go.run(result.instance);
+//		document.body.innerHTML = gfm.render("# Hello");
+//	});
+//	</script>
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+// options are the options that render enables by name.
+var options = map[string]func(*github_flavored_markdown.Options){
+	"comment":         func(o *github_flavored_markdown.Options) { o.Mode = github_flavored_markdown.CommentMode },
+	"footnotes":       func(o *github_flavored_markdown.Options) { o.Footnotes = true },
+	"emoji":           func(o *github_flavored_markdown.Options) { o.Emoji = true },
+	"math":            func(o *github_flavored_markdown.Options) { o.Math = true },
+	"mermaid":         func(o *github_flavored_markdown.Options) { o.Mermaid = true },
+	"frontMatter":     func(o *github_flavored_markdown.Options) { o.FrontMatter = true },
+	"toc":             func(o *github_flavored_markdown.Options) { o.TOC = true },
+	"definitionLists": func(o *github_flavored_markdown.Options) { o.DefinitionLists = true },
+	"spoilers":        func(o *github_flavored_markdown.Options) { o.Spoilers = true },
+	"richTableCells":  func(o *github_flavored_markdown.Options) { o.RichTableCells = true },
+	"tagFilter":       func(o *github_flavored_markdown.Options) { o.TagFilter = true },
+	"githubAnchors":   func(o *github_flavored_markdown.Options) { o.GitHubAnchors = true },
+	"headingIDs":      func(o *github_flavored_markdown.Options) { o.HeadingIDs = true },
+	"lineNumbers":     func(o *github_flavored_markdown.Options) { o.LineNumbers = true },
+	"langBadge":       func(o *github_flavored_markdown.Options) { o.CodeLangBadge = true },
+	"smartypants":     func(o *github_flavored_markdown.Options) { o.Smartypants = true },
+	"dirAuto":         func(o *github_flavored_markdown.Options) { o.DirAuto = true },
+}
+
+func main() {
+	js.Global().Set("gfm", js.ValueOf(map[string]interface{}{
+		"render": js.FuncOf(render),
+	}))
+	// Keep the functions available to JavaScript.
+	select {}
+}
+
+// render renders args[0], using the options enabled by args[1], if any.
+func render(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return js.Null()
+	}
+	var opts github_flavored_markdown.Options
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		for name, set := range options {
+			if args[1].Get(name).Truthy() {
+				set(&opts)
+			}
+		}
+	}
+	return string(github_flavored_markdown.MarkdownOptions([]byte(args[0].String()), opts))
+}
@@ -0,0 +1,308 @@
+package github_flavored_markdown_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestCodeLangBadge(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "```cmake\nproject(x)\n```\n",
+			want: `<div class="highlight highlight-cmake"><span class="lang-badge">cmake</span><pre><span class="k">project</span>(x)` + "\n" + `</pre></div>`,
+		},
+		{
+			// No badge for unlabeled blocks.
+			text: "```\nplain\n```\n",
+			want: `<pre><code>plain` + "\n" + `</code></pre>`,
+		},
+	}
+
+	for _, test := range tests {
+		opts := github_flavored_markdown.Options{CodeLangBadge: true}
+		if got := string(github_flavored_markdown.MarkdownOptions([]byte(test.text), opts)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestDefaultCodeLang(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			// Unlabeled block, highlighted as the default language.
+			text: "```\nall: app\n```\n",
+			want: `<div class="highlight highlight-makefile"><pre><span class="n">all</span>: app` + "\n" + `</pre></div>`,
+		},
+		{
+			// An explicit language overrides the default.
+			text: "```cmake\nproject(x)\n```\n",
+			want: `<div class="highlight highlight-cmake"><pre><span class="k">project</span>(x)` + "\n" + `</pre></div>`,
+		},
+		{
+			// Indented code blocks are not affected.
+			text: "    all: app\n",
+			want: `<pre><code>all: app` + "\n" + `</code></pre>`,
+		},
+	}
+
+	for _, test := range tests {
+		opts := github_flavored_markdown.Options{DefaultCodeLang: "makefile"}
+		if got := string(github_flavored_markdown.MarkdownOptions([]byte(test.text), opts)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestHighlightLanguages(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "```python\ndef f(x):\n    return \"s\" # c\n```\n",
+			want: `<div class="highlight highlight-python"><pre><span class="k">def</span> <span class="n">f</span><span class="p">(</span><span class="n">x</span><span class="p">):</span>` + "\n" +
+				`    <span class="k">return</span> <span class="s">&#34;s&#34;</span> <span class="c"># c</span>` + "\n" + `</pre></div>`,
+		},
+		{
+			// Unknown languages are escaped, but not highlighted.
+			text: "```nosuchlang\na < b\n```\n",
+			want: `<div class="highlight highlight-nosuchlang"><pre>a &lt; b` + "\n" + `</pre></div>`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestWithHighlighter(t *testing.T) {
+	h := github_flavored_markdown.HighlighterFunc(func(src []byte, lang string) ([]byte, bool) {
+		if lang != "upper" {
+			return nil, false
+		}
+		return []byte(`<span class="k">` + strings.ToUpper(string(src)) + `</span>`), true
+	})
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "```upper\nhello\n```\n",
+			want: `<div class="highlight highlight-upper"><pre><span class="k">HELLO` + "\n" + `</span></pre></div>`,
+		},
+		{
+			// Falls back to the built-in highlighters.
+			text: "```cmake\nproject(x)\n```\n",
+			want: `<div class="highlight highlight-cmake"><pre><span class="k">project</span>(x)` + "\n" + `</pre></div>`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithHighlighter(h))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestParallelHighlighting(t *testing.T) {
+	// Options.Highlighter is called for one block at a time, and the
+	// built-in highlighters highlight the blocks it doesn't.
+	var mu sync.Mutex
+	var running, maxRunning int
+	h := github_flavored_markdown.HighlighterFunc(func(src []byte, lang string) ([]byte, bool) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if lang != "upper" {
+			return nil, false
+		}
+		return []byte(`<span class="k">` + strings.ToUpper(string(src)) + `</span>`), true
+	})
+
+	var text, want string
+	for _, s := range []string{"a", "b", "c", "d", "e", "f"} {
+		text += "```upper\n" + s + "\n```\n\n```cmake\nproject(" + s + ")\n```\n\n"
+		want += `<div class="highlight highlight-upper"><pre><span class="k">` + strings.ToUpper(s) + "\n" + `</span></pre></div>` +
+			`<div class="highlight highlight-cmake"><pre><span class="k">project</span>(` + s + ")\n" + `</pre></div>`
+	}
+
+	// The output is in document order.
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithHighlighter(h))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+	if maxRunning > 1 {
+		t.Errorf("Highlight was called for %d blocks at a time", maxRunning)
+	}
+}
+
+func TestHighlightLimits(t *testing.T) {
+	text := "```cmake\nproject(x)\nproject(y)\n```\n"
+	highlighted := `<div class="highlight highlight-cmake"><pre><span class="k">project</span>(x)` + "\n" + `<span class="k">project</span>(y)` + "\n" + `</pre></div>`
+	escaped := `<div class="highlight highlight-cmake"><pre>project(x)` + "\n" + `project(y)` + "\n" + `</pre></div>`
+
+	tests := []struct {
+		size  int
+		lines int
+		want  string
+	}{
+		{size: 0, lines: 0, want: highlighted},
+		{size: 22, lines: 2, want: highlighted},
+		{size: 21, lines: 2, want: escaped},
+		{size: 22, lines: 1, want: escaped},
+		{size: -1, lines: -1, want: highlighted},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithHighlightLimits(test.size, test.lines))); got != test.want {
+			t.Errorf("size %d, lines %d:\ngot %q\nwant %q", test.size, test.lines, got, test.want)
+		}
+	}
+
+	// Large code blocks are not highlighted by default.
+	text = "```cmake\n" + strings.Repeat("project(x)\n", github_flavored_markdown.DefaultMaxHighlightLines+1) + "```\n"
+	if got := string(github_flavored_markdown.Markdown([]byte(text))); strings.Contains(got, `<span class="k">`) {
+		t.Error("large code block highlighted")
+	}
+}
+
+func TestLineNumbers(t *testing.T) {
+	text := "```cmake\n# a\nproject(x)\n```\n"
+	want := `<div class="highlight highlight-cmake"><pre><span class="ln">1</span><span class="c"># a</span>` + "\n" +
+		`<span class="ln">2</span><span class="k">project</span>(x)` + "\n" + `</pre></div>`
+
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithLineNumbers())); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestHighlightLines(t *testing.T) {
+	text := "```cmake {2}\n# a\nproject(x)\n```\n"
+	want := `<div class="highlight highlight-cmake"><pre><span class="c"># a</span>` + "\n" +
+		`<span class="hl"><span class="k">project</span>(x)</span>` + "\n" + `</pre></div>`
+
+	if got := string(github_flavored_markdown.Markdown([]byte(text))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestCodeTitle(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "```cmake title=CMakeLists.txt\nproject(x)\n```\n",
+			want: `<div class="highlight highlight-cmake"><div class="highlight-title">CMakeLists.txt</div><pre><span class="k">project</span>(x)` + "\n" + `</pre></div>`,
+		},
+		{
+			text: "```cmake:CMakeLists.txt\nproject(x)\n```\n",
+			want: `<div class="highlight highlight-cmake"><div class="highlight-title">CMakeLists.txt</div><pre><span class="k">project</span>(x)` + "\n" + `</pre></div>`,
+		},
+		{
+			// Quoted titles, and other metadata in the info string.
+			text: "```cmake {1} title=\"a <b>.txt\"\nproject(x)\n```\n",
+			want: `<div class="highlight highlight-cmake"><div class="highlight-title">a &lt;b&gt;.txt</div><pre><span class="hl"><span class="k">project</span>(x)</span>` + "\n" + `</pre></div>`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestLanguageAliases(t *testing.T) {
+	tests := []struct {
+		text string
+		opts []github_flavored_markdown.Option
+		want string
+	}{
+		{
+			text: "```py\nx\n```\n",
+			want: `<div class="highlight highlight-python"><pre><span class="n">x</span>` + "\n" + `</pre></div>`,
+		},
+		{
+			text: "```mk\nall: app\n```\n",
+			want: `<div class="highlight highlight-makefile"><pre><span class="n">all</span>: app` + "\n" + `</pre></div>`,
+		},
+		{
+			// Languages are matched case-insensitively.
+			text: "```CMake\nproject(x)\n```\n",
+			want: `<div class="highlight highlight-cmake"><pre><span class="k">project</span>(x)` + "\n" + `</pre></div>`,
+		},
+		{
+			text: "```PY\nx\n```\n",
+			want: `<div class="highlight highlight-python"><pre><span class="n">x</span>` + "\n" + `</pre></div>`,
+		},
+		{
+			// Custom aliases take precedence.
+			text: "```py\nproject(x)\n```\n",
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithLanguageAliases(map[string]string{"py": "cmake"})},
+			want: `<div class="highlight highlight-cmake"><pre><span class="k">project</span>(x)` + "\n" + `</pre></div>`,
+		},
+		{
+			text: "```Dockerfile\nFROM x\n```\n",
+			want: `<div class="highlight highlight-docker"><pre><span class="k">FROM</span><span class="s"> x</span>` + "\n" + `</pre></div>`,
+		},
+		{
+			// Custom names are lowercased.
+			text: "```PY\nproject(x)\n```\n",
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithLanguageAliases(map[string]string{"Py": "CMake"})},
+			want: `<div class="highlight highlight-cmake"><pre><span class="k">project</span>(x)` + "\n" + `</pre></div>`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), test.opts...)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestWithCodeClasses(t *testing.T) {
+	text := []byte("```cmake\nproject(x \"y\")\n```\n")
+	tests := []struct {
+		scheme *github_flavored_markdown.ClassScheme
+		want   string
+	}{
+		{
+			scheme: github_flavored_markdown.HLJSClasses,
+			want:   `<div class="highlight highlight-cmake hljs"><pre class="language-cmake"><span class="hljs-keyword">project</span>(x <span class="hljs-string">&#34;y&#34;</span>)` + "\n" + `</pre></div>`,
+		},
+		{
+			scheme: github_flavored_markdown.PrismClasses,
+			want:   `<div class="highlight highlight-cmake"><pre class="language-cmake"><span class="token keyword">project</span>(x <span class="token string">&#34;y&#34;</span>)` + "\n" + `</pre></div>`,
+		},
+		{
+			scheme: &github_flavored_markdown.ClassScheme{Classes: map[string]string{"k": "kw", "s": ""}},
+			want:   `<div class="highlight highlight-cmake"><pre><span class="kw">project</span>(x <span>&#34;y&#34;</span>)` + "\n" + `</pre></div>`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown(text, github_flavored_markdown.WithCodeClasses(test.scheme))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
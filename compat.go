@@ -0,0 +1,285 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// CompatibilityLevel is how closely Compare requires rendered HTML to match
+// that of the GitHub Markdown API.
+type CompatibilityLevel int
+
+const (
+	// TextCompatibility requires the same top-level blocks, with the same
+	// text. The elements within them can differ.
+	TextCompatibility CompatibilityLevel = iota
+
+	// StructureCompatibility requires the same elements, nested the same
+	// way, with the same text. Their attributes can differ.
+	StructureCompatibility
+
+	// AttributeCompatibility requires the same elements, text and
+	// attributes, other than the attributes that only style elements or
+	// don't change the document, such as class and rel.
+	AttributeCompatibility
+)
+
+// A Difference is a difference between two documents, found by Compare.
+type Difference struct {
+	Path string // Path of the differing node, such as "/ul[1]/li[2]/text()[1]".
+	Got  string // The node, rendered as normalized HTML, or "" if it's missing.
+	Want string // Likewise.
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s:\n\tgot  %s\n\twant %s", d.Path, d.Got, d.Want)
+}
+
+// Compare compares got, HTML rendered by this package, with want, rendered
+// from the same Markdown by the GitHub Markdown API, and returns their
+// differences at the given level, or nil if they're equivalent. The
+// differences are in order, with a path to each in the documents.
+//
+// Before comparing, the markup that differs but renders the same document
+// is normalized in both: heading anchor links, the wrappers of headings,
+// tables and code blocks, the spans of highlighted code and whitespace
+// between elements are removed, and other runs of whitespace outside of
+// code blocks are collapsed.
+func Compare(got, want []byte, level CompatibilityLevel) []Difference {
+	g, w := compatNode(got), compatNode(want)
+	var diffs []Difference
+	compareChildren(&diffs, "", g, w, level)
+	return diffs
+}
+
+// compatNode parses the HTML fragment b into the children of a body
+// element, normalized for comparison.
+func compatNode(b []byte) *html.Node {
+	body := &html.Node{Type: html.ElementNode, Data: atom.Body.String(), DataAtom: atom.Body}
+	nodes, _ := html.ParseFragment(bytes.NewReader(b), body)
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	normalizeCompat(body)
+	return body
+}
+
+// compatWrappers are the classes of the elements that wrap headings and
+// code blocks, which normalizeCompat replaces with their children.
+var compatWrappers = map[string]bool{
+	"markdown-heading":          true, // GitHub's headings.
+	"highlight":                 true, // Highlighted code blocks.
+	"snippet-clipboard-content": true, // GitHub's code blocks without a language.
+}
+
+// normalizeCompat normalizes the children of n for Compare.
+func normalizeCompat(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case c.Type == html.CommentNode,
+			c.Type == html.ElementNode && hasClass(c, "anchor"):
+			n.RemoveChild(c)
+		case c.Type == html.ElementNode && (isCompatWrapper(c) || c.Data == "markdown-accessiblity-table"):
+			// Normalize the unwrapped children too.
+			next = c.FirstChild
+			if next == nil {
+				next = c.NextSibling
+			}
+			for cc := c.FirstChild; cc != nil; cc = c.FirstChild {
+				c.RemoveChild(cc)
+				n.InsertBefore(cc, c)
+			}
+			n.RemoveChild(c)
+		case c.Type == html.ElementNode && c.DataAtom == atom.Pre:
+			normalizePre(c)
+		case c.Type == html.ElementNode:
+			normalizeCompat(c)
+		case c.Type == html.TextNode:
+			c.Data = collapseSpace(c.Data)
+		}
+		c = next
+	}
+	// Join the text that was separated by removed nodes, and drop the
+	// whitespace between elements.
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.TextNode {
+			for next != nil && next.Type == html.TextNode {
+				c.Data = collapseSpace(c.Data + next.Data)
+				n.RemoveChild(next)
+				next = c.NextSibling
+			}
+			if strings.TrimSpace(c.Data) == "" {
+				n.RemoveChild(c)
+			}
+		}
+		c = next
+	}
+}
+
+// collapseSpace returns s with each run of whitespace replaced by a single
+// space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		b.WriteRune(r)
+		space = false
+	}
+	return b.String()
+}
+
+// normalizePre replaces the content of the <pre> element n with its text,
+// without the final newline, within its <code> element, if any.
+func normalizePre(n *html.Node) {
+	text := &html.Node{Type: html.TextNode, Data: strings.TrimSuffix(textContent(n), "\n")}
+	var code *html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.Code {
+			code = c
+			break
+		}
+	}
+	for n.FirstChild != nil {
+		n.RemoveChild(n.FirstChild)
+	}
+	if code != nil {
+		for code.FirstChild != nil {
+			code.RemoveChild(code.FirstChild)
+		}
+		code.AppendChild(text)
+		n.AppendChild(code)
+		return
+	}
+	n.AppendChild(text)
+}
+
+// isCompatWrapper reports whether n is an element that wraps headings or
+// code blocks.
+func isCompatWrapper(n *html.Node) bool {
+	if n.DataAtom != atom.Div {
+		return false
+	}
+	for _, class := range strings.Fields(attrValue(n, "class")) {
+		if compatWrappers[class] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasClass reports whether n has the given class.
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attrValue(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent returns the concatenated text of n and its descendants.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+// compareChildren appends the differences between the children of got and
+// want, at path, to diffs.
+func compareChildren(diffs *[]Difference, path string, got, want *html.Node, level CompatibilityLevel) {
+	g, w := got.FirstChild, want.FirstChild
+	gIndex, wIndex := make(map[string]int), make(map[string]int)
+	for g != nil || w != nil {
+		var gPath, wPath string
+		if g != nil {
+			gPath = childPath(path, g, gIndex)
+		}
+		if w != nil {
+			wPath = childPath(path, w, wIndex)
+		}
+		switch {
+		case g == nil:
+			*diffs = append(*diffs, Difference{Path: wPath, Want: renderCompat(w)})
+		case w == nil:
+			*diffs = append(*diffs, Difference{Path: gPath, Got: renderCompat(g)})
+		case !sameCompat(g, w, level):
+			*diffs = append(*diffs, Difference{Path: wPath, Got: renderCompat(g), Want: renderCompat(w)})
+		case level > TextCompatibility && g.Type == html.ElementNode:
+			compareChildren(diffs, wPath, g, w, level)
+		}
+		if g != nil {
+			g = g.NextSibling
+		}
+		if w != nil {
+			w = w.NextSibling
+		}
+	}
+}
+
+// childPath returns the path of n, a child of the node at path, counting
+// the preceding siblings like it in index.
+func childPath(path string, n *html.Node, index map[string]int) string {
+	name := "text()"
+	if n.Type == html.ElementNode {
+		name = n.Data
+	}
+	index[name]++
+	return fmt.Sprintf("%s/%s[%d]", path, name, index[name])
+}
+
+// sameCompat reports whether the normalized nodes g and w are the same at
+// level, not counting their children above TextCompatibility.
+func sameCompat(g, w *html.Node, level CompatibilityLevel) bool {
+	if level == TextCompatibility {
+		return collapseSpace(textContent(g)) == collapseSpace(textContent(w))
+	}
+	if g.Type != w.Type || g.Data != w.Data {
+		return false
+	}
+	return level < AttributeCompatibility || compatAttrs(g) == compatAttrs(w)
+}
+
+// compatIgnoredAttrs are the attributes that AttributeCompatibility ignores.
+var compatIgnoredAttrs = map[string]bool{
+	"class": true, "style": true, "dir": true, "rel": true, "target": true,
+}
+
+// compatAttrs returns the attributes of n that AttributeCompatibility
+// compares, sorted.
+func compatAttrs(n *html.Node) string {
+	var attrs []string
+	for _, a := range n.Attr {
+		if compatIgnoredAttrs[a.Key] || strings.HasPrefix(a.Key, "aria-") {
+			continue
+		}
+		attrs = append(attrs, fmt.Sprintf("%s=%q", a.Key, a.Val))
+	}
+	sort.Strings(attrs)
+	return strings.Join(attrs, " ")
+}
+
+// renderCompat returns n rendered as HTML.
+func renderCompat(n *html.Node) string {
+	var buf bytes.Buffer
+	html.Render(&buf, n)
+	return buf.String()
+}
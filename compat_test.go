@@ -0,0 +1,154 @@
+package github_flavored_markdown_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+var (
+	update = flag.Bool("update", false, "update the cached GitHub Markdown API responses in testdata/github")
+	compat = flag.String("compat", "structure", "compatibility level of TestGitHubCompatibility: text, structure or attributes")
+)
+
+var compatibilityLevels = map[string]github_flavored_markdown.CompatibilityLevel{
+	"text":       github_flavored_markdown.TextCompatibility,
+	"structure":  github_flavored_markdown.StructureCompatibility,
+	"attributes": github_flavored_markdown.AttributeCompatibility,
+}
+
+// TestGitHubCompatibility renders the documents in testdata/github and
+// compares the output with the responses of the GitHub Markdown API for
+// them, cached in the .html files next to them. Run it with -update to
+// update the responses from the API, with the token in $GITHUB_TOKEN, if
+// set, and with -compat to compare at another level.
+func TestGitHubCompatibility(t *testing.T) {
+	level, ok := compatibilityLevels[*compat]
+	if !ok {
+		t.Fatalf("unknown compatibility level %q", *compat)
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", "github", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no documents in testdata/github")
+	}
+	compatible := 0
+	for _, file := range files {
+		text, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cached := strings.TrimSuffix(file, ".md") + ".html"
+		if *update {
+			want, err := githubMarkdown(text)
+			if err != nil {
+				t.Fatalf("%s: %v", file, err)
+			}
+			if err := os.WriteFile(cached, want, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(cached)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		diffs := github_flavored_markdown.Compare(github_flavored_markdown.Markdown(text), want, level)
+		for _, d := range diffs {
+			t.Errorf("%s: %v", file, d)
+		}
+		if len(diffs) == 0 {
+			compatible++
+		}
+	}
+	t.Logf("%d of %d documents compatible at level %s", compatible, len(files), *compat)
+}
+
+// githubMarkdown renders text with the GitHub Markdown API.
+func githubMarkdown(text []byte) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{"text": string(text), "mode": "markdown"})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://api.github.com/markdown", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub Markdown API: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		got, want string
+		level     github_flavored_markdown.CompatibilityLevel
+		diffs     []github_flavored_markdown.Difference
+	}{
+		{
+			// Heading anchors, wrappers, highlighting and whitespace.
+			got: `<h1><a name="a" class="anchor" href="#a"><span class="octicon octicon-link"></span></a>A <em>b</em></h1>
+
+<div class="highlight highlight-go"><pre><span class="k">x</span>  y
+</pre></div>`,
+			want: `<div class="markdown-heading"><h1 class="heading-element">A <em>b</em></h1><a id="user-content-a" class="anchor" href="#a"><span class="octicon octicon-link"></span></a></div>
+<div class="highlight highlight-source-go"><pre><span class="pl-k">x</span>  <span class="pl-s1">y</span></pre></div>`,
+			level: github_flavored_markdown.AttributeCompatibility,
+		},
+		{
+			got:   "<ul>\n<li>a <em>b</em></li>\n<li>c</li>\n</ul>\n",
+			want:  "<ul>\n<li>a <strong>b</strong></li>\n</ul>\n<p>d</p>\n",
+			level: github_flavored_markdown.StructureCompatibility,
+			diffs: []github_flavored_markdown.Difference{
+				{Path: "/ul[1]/li[1]/strong[1]", Got: "<em>b</em>", Want: "<strong>b</strong>"},
+				{Path: "/ul[1]/li[2]", Got: "<li>c</li>"},
+				{Path: "/p[1]", Want: "<p>d</p>"},
+			},
+		},
+		{
+			got:   `<p><a href="/a" rel="nofollow">a <em>b</em></a></p>`,
+			want:  `<p><a href="/a"><strong>a</strong> b</a></p>`,
+			level: github_flavored_markdown.TextCompatibility,
+		},
+		{
+			got:   `<p><a href="/a" class="x">a</a> <img src="b.png" alt="b"></p>`,
+			want:  `<p><a href="/b">a</a> <img alt="b" src="b.png"></p>`,
+			level: github_flavored_markdown.AttributeCompatibility,
+			diffs: []github_flavored_markdown.Difference{
+				{Path: "/p[1]/a[1]", Got: `<a href="/a" class="x">a</a>`, Want: `<a href="/b">a</a>`},
+			},
+		},
+		{
+			got:   `<p><a href="/a">a</a></p>`,
+			want:  `<p><a href="/b">a</a></p>`,
+			level: github_flavored_markdown.StructureCompatibility,
+		},
+	}
+	for _, tc := range tests {
+		if got := github_flavored_markdown.Compare([]byte(tc.got), []byte(tc.want), tc.level); !reflect.DeepEqual(got, tc.diffs) {
+			t.Errorf("%q, %q:\ngot %q\nwant %q", tc.got, tc.want, got, tc.diffs)
+		}
+	}
+}
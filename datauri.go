@@ -0,0 +1,54 @@
+package github_flavored_markdown
+
+import (
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// DefaultDataURITypes are the MIME types allowed in data URI images when
+// Options.DataURITypes is nil. They are the types the default policy allows.
+var DefaultDataURITypes = []string{"image/gif", "image/jpeg", "image/png", "image/svg+xml", "image/webp"}
+
+// dataURITypes returns the lowercase opts.DataURITypes, or
+// DefaultDataURITypes, sorted.
+func (opts Options) dataURITypes() []string {
+	types := opts.DataURITypes
+	if types == nil {
+		types = DefaultDataURITypes
+	}
+	sorted := make([]string, len(types))
+	for i, t := range types {
+		sorted[i] = strings.ToLower(t)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// allowDataURIImages extends p to allow base64 data URIs of types, up to
+// maxSize decoded bytes if maxSize is positive, like
+// bluemonday.Policy.AllowDataURIImages does without limits.
+func allowDataURIImages(p *bluemonday.Policy, maxSize int, types []string) {
+	p.AllowURLSchemeWithCustomPolicy("data", func(u *url.URL) bool {
+		if u.RawQuery != "" || u.Fragment != "" {
+			return false
+		}
+		i := strings.Index(u.Opaque, ";base64,")
+		if i < 0 {
+			return false
+		}
+		typ, data := strings.ToLower(u.Opaque[:i]), u.Opaque[i+len(";base64,"):]
+		j := sort.SearchStrings(types, typ)
+		if j == len(types) || types[j] != typ {
+			return false
+		}
+		if maxSize > 0 && base64.StdEncoding.DecodedLen(len(data)) > maxSize+2 {
+			return false
+		}
+		b, err := base64.StdEncoding.DecodeString(data)
+		return err == nil && (maxSize <= 0 || len(b) <= maxSize)
+	})
+}
@@ -0,0 +1,41 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestWithDataURILimits(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			// 8 bytes.
+			text: "![a](data:image/png;base64,iVBORw0KGgo=)",
+			want: `<p><img src="data:image/png;base64,iVBORw0KGgo=" alt="a"/></p>` + "\n",
+		},
+		{
+			// 12 bytes, too large.
+			text: "![b](data:image/png;base64,iVBORw0KGgoAAAAA)",
+			want: `<p><img alt="b"/></p>` + "\n",
+		},
+		{
+			// Type not allowed.
+			text: "![c](data:image/svg+xml;base64,PHN2Zy8+)",
+			want: `<p><img alt="c"/></p>` + "\n",
+		},
+		{
+			// Other images aren't affected.
+			text: "![d](https://example.com/d.png)",
+			want: `<p><img src="https://example.com/d.png" alt="d"/></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithDataURILimits(10, "image/png", "image/gif"))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestDefinitionLists(t *testing.T) {
+	text := "Term\n: Definition of *term*.\n\nOther\n: First.\n: Second.\n"
+
+	want := `<dl>
+<dt>Term</dt>
+<dd>Definition of <em>term</em>.</dd>
+<dt>Other</dt>
+<dd>First.</dd>
+<dd>Second.</dd>
+</dl>
+`
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithDefinitionLists())); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	// Without the option, definitions are plain paragraphs.
+	want = "<p>Term\n: Definition of <em>term</em>.</p>\n\n<p>Other\n: First.\n: Second.</p>\n"
+	if got := string(github_flavored_markdown.Markdown([]byte(text))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// detailsTagRE matches the opening or closing tag of a collapsible section.
+var detailsTagRE = regexp.MustCompile(`(?i)^</?(details|summary)[\t\n\f\r />]`)
+
+// detailsBlocks unwraps the paragraphs that start with a <details> or
+// <summary> tag, which blackfriday doesn't know as block tags, so that
+// collapsible sections aren't nested in <p> elements. A closing tag on the
+// last line of a paragraph is moved out of it. Markdown between the tags
+// is rendered, like on GitHub.
+func detailsBlocks(doc *bf.Node) {
+	var paragraphs []*bf.Node
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if entering && node.Type == bf.Paragraph {
+			paragraphs = append(paragraphs, node)
+		}
+		return bf.GoToNext
+	})
+	for _, p := range paragraphs {
+		if last := lastInline(p); last != nil && last.Type == bf.HTMLSpan && detailsTagRE.Match(last.Literal) &&
+			last.Prev != nil && last.Prev.Type == bf.Text && bytes.HasSuffix(last.Prev.Literal, []byte("\n")) {
+			last.Prev.Literal = bytes.TrimSuffix(last.Prev.Literal, []byte("\n"))
+			last.Unlink()
+			insertAfter(p, last)
+			insertAfter(last, newText([]byte("\n")))
+		}
+		if first := firstInline(p); first != nil && first.Type == bf.HTMLSpan && detailsTagRE.Match(first.Literal) {
+			for c := p.FirstChild; c != nil; c = p.FirstChild {
+				c.Unlink()
+				p.InsertBefore(c)
+			}
+			p.InsertBefore(newText([]byte("\n")))
+			p.Unlink()
+		}
+	}
+}
+
+// firstInline returns the first inline node of paragraph that isn't empty text.
+func firstInline(paragraph *bf.Node) *bf.Node {
+	c := paragraph.FirstChild
+	for c != nil && c.Type == bf.Text && len(c.Literal) == 0 {
+		c = c.Next
+	}
+	return c
+}
+
+// lastInline returns the last inline node of paragraph that isn't empty text.
+func lastInline(paragraph *bf.Node) *bf.Node {
+	c := paragraph.LastChild
+	for c != nil && c.Type == bf.Text && len(c.Literal) == 0 {
+		c = c.Prev
+	}
+	return c
+}
+
+// insertAfter inserts n as the next sibling of node.
+func insertAfter(node, n *bf.Node) {
+	if node.Next != nil {
+		node.Next.InsertBefore(n)
+	} else {
+		node.Parent.AppendChild(n)
+	}
+}
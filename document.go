@@ -0,0 +1,40 @@
+package github_flavored_markdown
+
+import (
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// Node is a node of a parsed document. It is the blackfriday node type,
+// so its fields and methods, such as Walk, can be used to inspect and
+// modify documents.
+type Node = bf.Node
+
+// Document is a parsed document, as returned by Parse.
+type Document struct {
+	Node *Node // Root node of the document.
+
+	text  []byte // Source text.
+	opts  Options
+	notes *footnotes
+}
+
+// Parse parses GitHub Flavored Markdown text, applying the same transforms,
+// such as autolinks and emoji, as Markdown does with the same options. The
+// document can be inspected and modified before rendering it with Render.
+func Parse(text []byte, opts ...Option) *Document {
+	o := newOptions(opts)
+	node, notes := newRenderer(o).parse(text)
+	return &Document{Node: node, text: text, opts: o, notes: notes}
+}
+
+// Render renders doc with the options it was parsed with. Rendering a
+// document that was not modified gives the same output as Markdown.
+func Render(doc *Document) []byte {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	unsanitized, err := newRenderer(doc.opts).render(buf, doc.Node, doc.notes)
+	if _, ok := err.(*OutputTooLargeError); ok {
+		return plainText(doc.text)
+	}
+	return sanitize(unsanitized, doc.opts)
+}
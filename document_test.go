@@ -0,0 +1,99 @@
+package github_flavored_markdown_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+	"golang.org/x/net/html"
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+func TestParseRender(t *testing.T) {
+	text := []byte("# Title\n\nSee [docs](https://example.com) and www.example.org.\n\n```go\nx := 1\n```\n")
+
+	doc := github_flavored_markdown.Parse(text)
+	if got, want := github_flavored_markdown.Render(doc), github_flavored_markdown.Markdown(text); !bytes.Equal(got, want) {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	var links []string
+	doc.Node.Walk(func(node *github_flavored_markdown.Node, entering bool) bf.WalkStatus {
+		if entering && node.Type == bf.Link {
+			links = append(links, string(node.LinkData.Destination))
+		}
+		return bf.GoToNext
+	})
+	if len(links) != 2 || links[0] != "https://example.com" || links[1] != "http://www.example.org" {
+		t.Errorf("got links %q", links)
+	}
+}
+
+func TestRenderModified(t *testing.T) {
+	doc := github_flavored_markdown.Parse([]byte("Hello *world*.\n"))
+	doc.Node.Walk(func(node *github_flavored_markdown.Node, entering bool) bf.WalkStatus {
+		if entering && node.Type == bf.Emph {
+			node.Type = bf.Strong
+		}
+		return bf.GoToNext
+	})
+
+	want := "<p>Hello <strong>world</strong>.</p>\n"
+	if got := string(github_flavored_markdown.Render(doc)); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestWithTransformer(t *testing.T) {
+	// A shortcode transformer, and one that runs after it.
+	shortcodes := func(doc *github_flavored_markdown.Node) {
+		doc.Walk(func(node *github_flavored_markdown.Node, entering bool) bf.WalkStatus {
+			if node.Type == bf.Text {
+				node.Literal = bytes.Replace(node.Literal, []byte("{{version}}"), []byte("v1.2.0"), -1)
+			}
+			return bf.GoToNext
+		})
+	}
+	upper := func(doc *github_flavored_markdown.Node) {
+		doc.Walk(func(node *github_flavored_markdown.Node, entering bool) bf.WalkStatus {
+			if node.Type == bf.Text {
+				node.Literal = bytes.ToUpper(node.Literal)
+			}
+			return bf.GoToNext
+		})
+	}
+
+	text := []byte("Install {{version}}.\n")
+	want := "<p>INSTALL V1.2.0.</p>\n"
+	got := string(github_flavored_markdown.Markdown(text,
+		github_flavored_markdown.WithTransformer(shortcodes),
+		github_flavored_markdown.WithTransformer(upper),
+	))
+	if got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestMarkdownNode(t *testing.T) {
+	text := []byte("# Title\n\nHello <script>alert()</script>*world*.\n")
+
+	doc := github_flavored_markdown.MarkdownNode(text)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), string(github_flavored_markdown.Markdown(text)); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	var elements []string
+	for n := doc.FirstChild; n != nil; n = n.NextSibling {
+		if n.Type == html.ElementNode {
+			elements = append(elements, n.Data)
+		}
+	}
+	if len(elements) != 2 || elements[0] != "h1" || elements[1] != "p" {
+		t.Errorf("got top-level elements %q", elements)
+	}
+}
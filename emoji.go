@@ -0,0 +1,94 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// emojiImages are GitHub's custom emoji, which have no Unicode equivalent.
+var emojiImages = map[string]bool{
+	"atom":      true,
+	"basecamp":  true,
+	"bowtie":    true,
+	"electron":  true,
+	"feelsgood": true,
+	"finnadie":  true,
+	"godmode":   true,
+	"neckbeard": true,
+	"octocat":   true,
+	"shipit":    true,
+	"suspect":   true,
+	"trollface": true,
+}
+
+// emojiImageURL is the URL of GitHub's custom emoji images.
+const emojiImageURL = "https://github.githubassets.com/images/icons/emoji/%s.png"
+
+// emoji replaces emoji shortcodes like :smile: in the text of doc
+// with the emoji. Shortcodes in custom are replaced with images of the
+// given URLs, taking precedence over standard emoji. Unknown shortcodes
+// are left as is.
+func emoji(doc *bf.Node, custom map[string]string) {
+	replaceText(doc, func(text []byte) []*bf.Node {
+		return emojify(text, custom)
+	})
+}
+
+// emojify returns the nodes that text with emoji shortcodes replaced
+// consists of, or nil if it has no known shortcodes.
+func emojify(text []byte, custom map[string]string) []*bf.Node {
+	var nodes []*bf.Node
+	var pending []byte // Text not yet added to nodes.
+	var replaced bool
+	for {
+		start := bytes.IndexByte(text, ':')
+		if start < 0 {
+			break
+		}
+		end := bytes.IndexByte(text[start+1:], ':')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		name := string(text[start+1 : end])
+
+		src, image := custom[name]
+		if !image && emojiImages[name] {
+			src, image = fmt.Sprintf(emojiImageURL, name), true
+		}
+		if image {
+			pending = append(pending, text[:start]...)
+			if len(pending) > 0 {
+				nodes = append(nodes, newText(pending))
+				pending = nil
+			}
+			nodes = append(nodes, newHTMLSpan(emojiImage(name, src)))
+		} else if s, ok := emojiTable[name]; ok {
+			pending = append(append(pending, text[:start]...), s...)
+		} else {
+			// The closing colon may start another shortcode.
+			pending = append(pending, text[:end]...)
+			text = text[end:]
+			continue
+		}
+		replaced = true
+		text = text[end+1:]
+	}
+	if !replaced {
+		return nil
+	}
+	if pending = append(pending, text...); len(pending) > 0 {
+		nodes = append(nodes, newText(pending))
+	}
+	return nodes
+}
+
+// emojiImage returns an img element showing the emoji with the given
+// shortcode name.
+func emojiImage(name, src string) string {
+	alt := template.HTMLEscapeString(":" + name + ":")
+	return fmt.Sprintf(`<img class="emoji" title="%s" alt="%s" src="%s" height="20" width="20">`, alt, alt, template.HTMLEscapeString(src))
+}
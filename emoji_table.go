@@ -0,0 +1,338 @@
+package github_flavored_markdown
+
+// emojiTable maps emoji shortcodes, without colons, to Unicode emoji.
+// It covers the commonly used part of GitHub's emoji list.
+var emojiTable = map[string]string{
+	"+1":                           "👍",
+	"-1":                           "👎",
+	"100":                          "💯",
+	"1234":                         "🔢",
+	"1st_place_medal":              "🥇",
+	"abc":                          "🔤",
+	"airplane":                     "✈️",
+	"alarm_clock":                  "⏰",
+	"alien":                        "👽",
+	"angry":                        "😠",
+	"ant":                          "🐜",
+	"apple":                        "🍎",
+	"arrow_down":                   "⬇️",
+	"arrow_left":                   "⬅️",
+	"arrow_right":                  "➡️",
+	"arrow_up":                     "⬆️",
+	"arrows_counterclockwise":      "🔄",
+	"art":                          "🎨",
+	"astonished":                   "😲",
+	"balloon":                      "🎈",
+	"ballot_box_with_check":        "☑️",
+	"banana":                       "🍌",
+	"bangbang":                     "‼️",
+	"bar_chart":                    "📊",
+	"basketball":                   "🏀",
+	"bear":                         "🐻",
+	"bee":                          "🐝",
+	"beer":                         "🍺",
+	"beers":                        "🍻",
+	"beetle":                       "🪲",
+	"bell":                         "🔔",
+	"bike":                         "🚲",
+	"bird":                         "🐦",
+	"birthday":                     "🎂",
+	"black_circle":                 "⚫",
+	"black_square_button":          "🔲",
+	"blue_heart":                   "💙",
+	"blush":                        "😊",
+	"book":                         "📖",
+	"bookmark":                     "🔖",
+	"books":                        "📚",
+	"boom":                         "💥",
+	"bow":                          "🙇",
+	"broken_heart":                 "💔",
+	"bug":                          "🐛",
+	"bulb":                         "💡",
+	"cactus":                       "🌵",
+	"cake":                         "🍰",
+	"calendar":                     "📆",
+	"call_me_hand":                 "🤙",
+	"car":                          "🚗",
+	"cat":                          "🐱",
+	"cd":                           "💿",
+	"champagne":                    "🍾",
+	"chart_with_downwards_trend":   "📉",
+	"chart_with_upwards_trend":     "📈",
+	"checkered_flag":               "🏁",
+	"cherries":                     "🍒",
+	"cherry_blossom":               "🌸",
+	"clap":                         "👏",
+	"clinking_glasses":             "🥂",
+	"clipboard":                    "📋",
+	"closed_lock_with_key":         "🔐",
+	"cloud":                        "☁️",
+	"coffee":                       "☕",
+	"cold_sweat":                   "😰",
+	"collision":                    "💥",
+	"computer":                     "💻",
+	"confetti_ball":                "🎊",
+	"confused":                     "😕",
+	"construction":                 "🚧",
+	"construction_worker":          "👷",
+	"cookie":                       "🍪",
+	"cool":                         "🆒",
+	"credit_card":                  "💳",
+	"crescent_moon":                "🌙",
+	"crossed_fingers":              "🤞",
+	"crown":                        "👑",
+	"cry":                          "😢",
+	"dancer":                       "💃",
+	"dark_sunglasses":              "🕶️",
+	"dart":                         "🎯",
+	"dash":                         "💨",
+	"date":                         "📅",
+	"deciduous_tree":               "🌳",
+	"desktop_computer":             "🖥️",
+	"disappointed":                 "😞",
+	"dizzy_face":                   "😵",
+	"dog":                          "🐶",
+	"dollar":                       "💵",
+	"dolphin":                      "🐬",
+	"doughnut":                     "🍩",
+	"dragon":                       "🐉",
+	"droplet":                      "💧",
+	"dvd":                          "📀",
+	"earth_americas":               "🌎",
+	"email":                        "📧",
+	"envelope":                     "✉️",
+	"evergreen_tree":               "🌲",
+	"exclamation":                  "❗",
+	"expressionless":               "😑",
+	"eyeglasses":                   "👓",
+	"eyes":                         "👀",
+	"facepalm":                     "🤦",
+	"fallen_leaf":                  "🍂",
+	"fearful":                      "😨",
+	"fire":                         "🔥",
+	"fish":                         "🐟",
+	"fist":                         "✊",
+	"floppy_disk":                  "💾",
+	"flushed":                      "😳",
+	"football":                     "🏈",
+	"four_leaf_clover":             "🍀",
+	"fox_face":                     "🦊",
+	"free":                         "🆓",
+	"fries":                        "🍟",
+	"frowning":                     "😦",
+	"game_die":                     "🎲",
+	"gear":                         "⚙️",
+	"gem":                          "💎",
+	"ghost":                        "👻",
+	"gift":                         "🎁",
+	"globe_with_meridians":         "🌐",
+	"green_apple":                  "🍏",
+	"green_circle":                 "🟢",
+	"green_heart":                  "💚",
+	"grey_exclamation":             "❕",
+	"grey_question":                "❔",
+	"grin":                         "😁",
+	"grinning":                     "😀",
+	"hamburger":                    "🍔",
+	"hammer":                       "🔨",
+	"hammer_and_wrench":            "🛠️",
+	"handshake":                    "🤝",
+	"hankey":                       "💩",
+	"headphones":                   "🎧",
+	"hear_no_evil":                 "🙉",
+	"heart":                        "❤️",
+	"heart_eyes":                   "😍",
+	"heavy_check_mark":             "✔️",
+	"heavy_exclamation_mark":       "❗",
+	"heavy_minus_sign":             "➖",
+	"heavy_multiplication_x":       "✖️",
+	"heavy_plus_sign":              "➕",
+	"herb":                         "🌿",
+	"honeybee":                     "🐝",
+	"hospital":                     "🏥",
+	"hourglass":                    "⌛",
+	"hourglass_flowing_sand":       "⏳",
+	"house":                        "🏠",
+	"hugs":                         "🤗",
+	"hushed":                       "😯",
+	"inbox_tray":                   "📥",
+	"information_source":           "ℹ️",
+	"innocent":                     "😇",
+	"interrobang":                  "⁉️",
+	"iphone":                       "📱",
+	"jeans":                        "👖",
+	"joy":                          "😂",
+	"key":                          "🔑",
+	"keyboard":                     "⌨️",
+	"kissing_heart":                "😘",
+	"large_blue_circle":            "🔵",
+	"large_blue_diamond":           "🔷",
+	"large_orange_diamond":         "🔶",
+	"laughing":                     "😆",
+	"lemon":                        "🍋",
+	"link":                         "🔗",
+	"lipstick":                     "💄",
+	"lock":                         "🔒",
+	"loudspeaker":                  "📢",
+	"mag":                          "🔍",
+	"mag_right":                    "🔎",
+	"mailbox":                      "📫",
+	"man_shrugging":                "🤷‍♂️",
+	"man_technologist":             "👨‍💻",
+	"mask":                         "😷",
+	"medal_sports":                 "🏅",
+	"mega":                         "📣",
+	"memo":                         "📝",
+	"metal":                        "🤘",
+	"microscope":                   "🔬",
+	"moneybag":                     "💰",
+	"monkey":                       "🐒",
+	"mortar_board":                 "🎓",
+	"mouse":                        "🐭",
+	"muscle":                       "💪",
+	"musical_note":                 "🎵",
+	"nail_care":                    "💅",
+	"necktie":                      "👔",
+	"negative_squared_cross_mark":  "❎",
+	"nerd_face":                    "🤓",
+	"neutral_face":                 "😐",
+	"new":                          "🆕",
+	"ninja":                        "🥷",
+	"no_bell":                      "🔕",
+	"no_entry":                     "⛔",
+	"no_entry_sign":                "🚫",
+	"notes":                        "🎶",
+	"nut_and_bolt":                 "🔩",
+	"ocean":                        "🌊",
+	"octopus":                      "🐙",
+	"office":                       "🏢",
+	"ok":                           "🆗",
+	"ok_hand":                      "👌",
+	"open_mouth":                   "😮",
+	"outbox_tray":                  "📤",
+	"owl":                          "🦉",
+	"package":                      "📦",
+	"page_facing_up":               "📄",
+	"panda_face":                   "🐼",
+	"paperclip":                    "📎",
+	"pencil":                       "📝",
+	"pencil2":                      "✏️",
+	"penguin":                      "🐧",
+	"pensive":                      "😔",
+	"pizza":                        "🍕",
+	"point_down":                   "👇",
+	"point_left":                   "👈",
+	"point_right":                  "👉",
+	"point_up":                     "☝️",
+	"poop":                         "💩",
+	"pray":                         "🙏",
+	"purple_heart":                 "💜",
+	"pushpin":                      "📌",
+	"question":                     "❓",
+	"rabbit":                       "🐰",
+	"radio_button":                 "🔘",
+	"rage":                         "😡",
+	"rainbow":                      "🌈",
+	"raised_hands":                 "🙌",
+	"raising_hand":                 "🙋",
+	"recycle":                      "♻️",
+	"red_circle":                   "🔴",
+	"relieved":                     "😌",
+	"repeat":                       "🔁",
+	"ring":                         "💍",
+	"robot":                        "🤖",
+	"rocket":                       "🚀",
+	"rofl":                         "🤣",
+	"roll_eyes":                    "🙄",
+	"rose":                         "🌹",
+	"rotating_light":               "🚨",
+	"running":                      "🏃",
+	"scream":                       "😱",
+	"see_no_evil":                  "🙈",
+	"seedling":                     "🌱",
+	"selfie":                       "🤳",
+	"ship":                         "🚢",
+	"shirt":                        "👕",
+	"shrug":                        "🤷",
+	"skull":                        "💀",
+	"sleeping":                     "😴",
+	"sleepy":                       "😪",
+	"slightly_smiling_face":        "🙂",
+	"small_red_triangle":           "🔺",
+	"small_red_triangle_down":      "🔻",
+	"smile":                        "😄",
+	"smiley":                       "😃",
+	"smirk":                        "😏",
+	"snail":                        "🐌",
+	"snake":                        "🐍",
+	"snowflake":                    "❄️",
+	"sob":                          "😭",
+	"soccer":                       "⚽",
+	"sos":                          "🆘",
+	"sparkles":                     "✨",
+	"sparkling_heart":              "💖",
+	"speak_no_evil":                "🙊",
+	"speech_balloon":               "💬",
+	"star":                         "⭐",
+	"star2":                        "🌟",
+	"stop_sign":                    "🛑",
+	"stopwatch":                    "⏱️",
+	"strawberry":                   "🍓",
+	"stuck_out_tongue":             "😛",
+	"stuck_out_tongue_winking_eye": "😜",
+	"sunflower":                    "🌻",
+	"sunglasses":                   "😎",
+	"sunny":                        "☀️",
+	"sweat_drops":                  "💦",
+	"sweat_smile":                  "😅",
+	"taco":                         "🌮",
+	"tada":                         "🎉",
+	"tea":                          "🍵",
+	"technologist":                 "🧑‍💻",
+	"telescope":                    "🔭",
+	"test_tube":                    "🧪",
+	"thinking":                     "🤔",
+	"thought_balloon":              "💭",
+	"thumbsdown":                   "👎",
+	"thumbsup":                     "👍",
+	"tired_face":                   "😫",
+	"toolbox":                      "🧰",
+	"tophat":                       "🎩",
+	"triangular_flag_on_post":      "🚩",
+	"triumph":                      "😤",
+	"trophy":                       "🏆",
+	"turtle":                       "🐢",
+	"umbrella":                     "☔",
+	"unamused":                     "😒",
+	"unicorn":                      "🦄",
+	"unlock":                       "🔓",
+	"up":                           "🆙",
+	"upside_down_face":             "🙃",
+	"v":                            "✌️",
+	"video_game":                   "🎮",
+	"walking":                      "🚶",
+	"warning":                      "⚠️",
+	"watch":                        "⌚",
+	"watermelon":                   "🍉",
+	"wave":                         "👋",
+	"weary":                        "😩",
+	"whale":                        "🐳",
+	"white_check_mark":             "✅",
+	"white_circle":                 "⚪",
+	"white_flag":                   "🏳️",
+	"white_square_button":          "🔳",
+	"wine_glass":                   "🍷",
+	"wink":                         "😉",
+	"woman_shrugging":              "🤷‍♀️",
+	"woman_technologist":           "👩‍💻",
+	"worried":                      "😟",
+	"wrench":                       "🔧",
+	"writing_hand":                 "✍️",
+	"x":                            "❌",
+	"yellow_circle":                "🟡",
+	"yellow_heart":                 "💛",
+	"yum":                          "😋",
+	"zap":                          "⚡",
+	"zipper_mouth_face":            "🤐",
+	"zzz":                          "💤",
+}
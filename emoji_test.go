@@ -0,0 +1,76 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestEmoji(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "Looks good :+1: :smile:",
+			want: "<p>Looks good 👍 😄</p>\n",
+		},
+		{
+			// Unknown shortcodes and times are left as is.
+			text: "At 10:30:00 :nope:smile:",
+			want: "<p>At 10:30:00 :nope😄</p>\n",
+		},
+		{
+			text: "**:tada:** `:tada:`",
+			want: "<p><strong>🎉</strong> <code>:tada:</code></p>\n",
+		},
+		{
+			text: "Ready :shipit:",
+			want: `<p>Ready <img class="emoji" title=":shipit:" alt=":shipit:" src="https://github.githubassets.com/images/icons/emoji/shipit.png" height="20" width="20"></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithEmoji())); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+
+	// Shortcodes are left as is by default.
+	if got, want := string(github_flavored_markdown.Markdown([]byte(":smile:"))), "<p>:smile:</p>\n"; got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestCustomEmoji(t *testing.T) {
+	custom := map[string]string{
+		"party_parrot": "https://example.com/emoji/parrot.gif",
+		"smile":        "https://example.com/emoji/smile.png",
+		"evil":         "javascript:alert(1)",
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "Yay :party_parrot:!",
+			want: `<p>Yay <img class="emoji" title=":party_parrot:" alt=":party_parrot:" src="https://example.com/emoji/parrot.gif" height="20" width="20">!</p>` + "\n",
+		},
+		{
+			// Custom emoji take precedence over standard ones.
+			text: ":smile: :tada:",
+			want: `<p><img class="emoji" title=":smile:" alt=":smile:" src="https://example.com/emoji/smile.png" height="20" width="20"> 🎉</p>` + "\n",
+		},
+		{
+			// Unsafe URLs are sanitized away.
+			text: ":evil:",
+			want: `<p><img class="emoji" title=":evil:" alt=":evil:" height="20" width="20"></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithCustomEmoji(custom))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
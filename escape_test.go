@@ -0,0 +1,48 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAttrEscape(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: "", want: ""},
+		{src: "plain", want: "plain"},
+		{src: `<a href="x">&</a>`, want: "&lt;a href=&quot;x&quot;&gt;&amp;&lt;/a&gt;"},
+		{src: "'single' é <", want: "'single' é &lt;"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		attrEscape(&buf, []byte(test.src))
+		if got := buf.String(); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func BenchmarkAttrEscape(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		src  []byte
+	}{
+		{"Code", []byte(strings.Repeat("if a < b && c > d {\n\tfmt.Println(\"<a href='x'>\")\n}\n", 1000))},
+		{"Text", []byte(strings.Repeat("The quick brown fox jumps over the lazy dog.\n", 1000))},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var buf bytes.Buffer
+			b.SetBytes(int64(len(bm.src)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				attrEscape(&buf, bm.src)
+			}
+		})
+	}
+}
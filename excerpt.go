@@ -0,0 +1,100 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// excerptMarker ends the excerpt of a document, if it makes up a block of
+// its own.
+const excerptMarker = "<!-- more -->"
+
+// Excerpt returns an excerpt of GitHub Flavored Markdown text, rendered as
+// sanitized HTML like Markdown and as plain text like Text. The excerpt is
+// the text before a "<!-- more -->" marker, or else the first paragraph.
+// If maxRunes is positive, the excerpt is shortened to at most that many
+// characters of text, at a word boundary, followed by "…".
+func Excerpt(text []byte, maxRunes int, opts ...Option) (html, plain string) {
+	doc := Parse(text, opts...)
+	excerptBlocks(doc.Node)
+	if maxRunes > 0 {
+		truncateText(doc.Node, maxRunes)
+	}
+	return string(Render(doc)), strings.TrimSuffix(string(textRenderer{}.render(doc)), "\n")
+}
+
+// excerptBlocks removes the top-level blocks of doc that aren't part of
+// its excerpt.
+func excerptBlocks(doc *bf.Node) {
+	for block := doc.FirstChild; block != nil; block = block.Next {
+		if block.Type == bf.HTMLBlock && bytes.EqualFold(bytes.TrimSpace(block.Literal), []byte(excerptMarker)) {
+			unlinkFrom(block)
+			return
+		}
+	}
+
+	first := doc.FirstChild
+	for first != nil && first.Type != bf.Paragraph {
+		first = first.Next
+	}
+	for doc.FirstChild != first {
+		doc.FirstChild.Unlink()
+	}
+	if first != nil {
+		unlinkFrom(first.Next)
+	}
+}
+
+// unlinkFrom unlinks node and its following siblings.
+func unlinkFrom(node *bf.Node) {
+	for node != nil {
+		next := node.Next
+		node.Unlink()
+		node = next
+	}
+}
+
+// truncateText shortens the text of doc to at most n runes, at a word
+// boundary, ending it with "…" and removing the nodes that follow.
+func truncateText(doc *bf.Node, n int) {
+	var truncated bool
+	var rest []*bf.Node
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		switch {
+		case !entering:
+			return bf.GoToNext
+		case truncated:
+			rest = append(rest, node)
+			return bf.SkipChildren
+		case node.Type != bf.Text && node.Type != bf.Code:
+			return bf.GoToNext
+		}
+		runes := []rune(string(node.Literal))
+		if len(runes) <= n {
+			n -= len(runes)
+			return bf.GoToNext
+		}
+		node.Literal = []byte(truncateWords(runes, n) + "…")
+		truncated = true
+		return bf.GoToNext
+	})
+	for _, node := range rest {
+		node.Unlink()
+	}
+}
+
+// truncateWords returns the first n runes of text, without a trailing partial
+// word, unless that is all there is.
+func truncateWords(text []rune, n int) string {
+	cut := n
+	for i := n; i > 0; i-- {
+		if unicode.IsSpace(text[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(text[:cut]), unicode.IsSpace)
+}
@@ -0,0 +1,54 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		text      string
+		maxRunes  int
+		wantHTML  string
+		wantPlain string
+	}{
+		{
+			// The first paragraph.
+			text:      "# Title\n\nFirst *paragraph*.\n\nSecond paragraph.\n",
+			wantHTML:  "<p>First <em>paragraph</em>.</p>\n",
+			wantPlain: "First paragraph.",
+		},
+		{
+			// Everything before the marker.
+			text:      "Intro.\n\n- a\n- b\n\n<!-- more -->\n\nRest.\n",
+			wantHTML:  "<p>Intro.</p>\n\n<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n",
+			wantPlain: "Intro.\n\n- a\n- b",
+		},
+		{
+			text:      "A fairly **long sentence** that goes on and on.\n",
+			maxRunes:  20,
+			wantHTML:  "<p>A fairly <strong>long…</strong></p>\n",
+			wantPlain: "A fairly long…",
+		},
+		{
+			text:      "Short.\n",
+			maxRunes:  20,
+			wantHTML:  "<p>Short.</p>\n",
+			wantPlain: "Short.",
+		},
+		{
+			text: "# Only a heading\n",
+		},
+	}
+
+	for _, test := range tests {
+		html, plain := github_flavored_markdown.Excerpt([]byte(test.text), test.maxRunes)
+		if html != test.wantHTML {
+			t.Errorf("\ngot %q\nwant %q", html, test.wantHTML)
+		}
+		if plain != test.wantPlain {
+			t.Errorf("\ngot %q\nwant %q", plain, test.wantPlain)
+		}
+	}
+}
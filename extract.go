@@ -0,0 +1,118 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// Link is a link in a document, as returned by Links.
+type Link struct {
+	URL   string // Destination, after any rewriting by the options.
+	Title string // Title, if any.
+	Text  string // Plain text of the link.
+
+	// Start and End are the byte offsets of the URL in the source text,
+	// or -1 if it could not be found, such as when the URL was rewritten.
+	Start, End int
+}
+
+// Links returns the links in GitHub Flavored Markdown text, including
+// autolinks, in document order.
+func Links(text []byte, opts ...Option) []Link {
+	var links []Link
+	doc := Parse(text, opts...)
+	loc := newSourceLocator(text, doc.opts)
+	doc.Node.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering || node.Type != bf.Link {
+			return bf.GoToNext
+		}
+		l := Link{
+			URL:   string(node.LinkData.Destination),
+			Title: string(node.LinkData.Title),
+			Text:  inlineText(node),
+		}
+		l.Start, l.End = loc.find(l.URL, l.Text)
+		links = append(links, l)
+		return bf.SkipChildren
+	})
+	return links
+}
+
+// Image is an image in a document, as returned by Images.
+type Image struct {
+	Src   string // Source URL, after any rewriting by the options.
+	Alt   string // Plain text alternative text.
+	Title string // Title, if any.
+
+	// Start and End are the byte offsets of the source URL in the source
+	// text, or -1 if it could not be found, such as when it was rewritten.
+	Start, End int
+}
+
+// Images returns the images in GitHub Flavored Markdown text, in document order.
+// Raw HTML images aren't included.
+func Images(text []byte, opts ...Option) []Image {
+	var images []Image
+	doc := Parse(text, opts...)
+	loc := newSourceLocator(text, doc.opts)
+	doc.Node.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering || node.Type != bf.Image {
+			return bf.GoToNext
+		}
+		img := Image{
+			Src:   string(node.LinkData.Destination),
+			Alt:   inlineText(node),
+			Title: string(node.LinkData.Title),
+		}
+		img.Start, img.End = loc.find(img.Src, "")
+		images = append(images, img)
+		return bf.SkipChildren
+	})
+	return images
+}
+
+// sourceLocator finds the source of successive links and images.
+type sourceLocator struct {
+	text   []byte
+	cursor int // Offset to search from.
+}
+
+func newSourceLocator(text []byte, opts Options) *sourceLocator {
+	loc := &sourceLocator{text: text}
+	if opts.FrontMatter {
+		body, _ := extractFrontMatter(text)
+		loc.cursor = len(text) - len(body)
+	}
+	return loc
+}
+
+// referenceDefinitionRE matches the start of a line with a link reference definition.
+var referenceDefinitionRE = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:`)
+
+// find returns the offsets of the first of the URL, or the link text of an
+// autolink, found after the previous one. It returns -1, -1 if neither is.
+func (loc *sourceLocator) find(url, text string) (start, end int) {
+	candidates := []string{url}
+	if url != text && (url == "http://"+text || url == "mailto:"+text) {
+		candidates = append(candidates, text)
+	}
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		i := bytes.Index(loc.text[loc.cursor:], []byte(c))
+		if i < 0 {
+			continue
+		}
+		start, end = loc.cursor+i, loc.cursor+i+len(c)
+		lineStart := bytes.LastIndexByte(loc.text[:start], '\n') + 1
+		if !referenceDefinitionRE.Match(loc.text[lineStart:start]) {
+			// Reference definitions may come after the following links.
+			loc.cursor = end
+		}
+		return start, end
+	}
+	return -1, -1
+}
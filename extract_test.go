@@ -0,0 +1,50 @@
+package github_flavored_markdown_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestLinks(t *testing.T) {
+	text := "See [the docs](https://example.com/docs \"Docs\") and [ref][r], www.example.org, " +
+		"<https://example.net> or mail me@example.com.\n\n[r]: https://example.com/ref\n"
+	want := []github_flavored_markdown.Link{
+		{URL: "https://example.com/docs", Title: "Docs", Text: "the docs", Start: 15, End: 39},
+		{URL: "https://example.com/ref", Text: "ref", Start: 131, End: 154},
+		{URL: "http://www.example.org", Text: "www.example.org", Start: 62, End: 77},
+		{URL: "https://example.net", Text: "https://example.net", Start: 80, End: 99},
+		{URL: "mailto:me@example.com", Text: "me@example.com", Start: 109, End: 123},
+	}
+
+	got := github_flavored_markdown.Links([]byte(text))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %+v\nwant %+v", got, want)
+	}
+	for _, l := range got {
+		if src := text[l.Start:l.End]; src != l.URL && src != l.Text {
+			t.Errorf("source of %q is %q", l.URL, src)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	text := "[![Build status](https://ci.example.com/badge.svg)](https://ci.example.com)\n\n" +
+		"![](a.png \"A\") <img src=\"raw.png\"> ![ref][r]\n\n[r]: b.png\n"
+	want := []github_flavored_markdown.Image{
+		{Src: "https://ci.example.com/badge.svg", Alt: "Build status", Start: 17, End: 49},
+		{Src: "a.png", Title: "A", Start: 81, End: 86},
+		{Src: "b.png", Alt: "ref", Start: 128, End: 133},
+	}
+
+	got := github_flavored_markdown.Images([]byte(text))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %+v\nwant %+v", got, want)
+	}
+	for _, img := range got {
+		if src := text[img.Start:img.End]; src != img.Src {
+			t.Errorf("source of %q is %q", img.Src, src)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// FenceRenderer renders fenced code blocks of some language as something
+// other than code, such as a diagram or a map.
+type FenceRenderer interface {
+	// RenderFence returns the HTML for a code block with source src in
+	// language lang. It reports false if it can't render src, in which
+	// case the block is rendered as code.
+	RenderFence(src []byte, lang string) ([]byte, bool)
+}
+
+// FenceRendererFunc is an adapter to allow the use of ordinary functions as FenceRenderers.
+type FenceRendererFunc func(src []byte, lang string) ([]byte, bool)
+
+// RenderFence calls f(src, lang).
+func (f FenceRendererFunc) RenderFence(src []byte, lang string) ([]byte, bool) {
+	return f(src, lang)
+}
+
+// RichFence renders a code block with structured data, such as GeoJSON,
+// TopoJSON or STL, like GitHub does for its interactive viewers, as
+//
+//	<div class="rich-fence" data-type="geojson"><pre>...</pre></div>
+//
+// with the escaped source in the <pre> element, for a page to hydrate a viewer
+// from. JSON languages must be valid JSON.
+var RichFence FenceRenderer = FenceRendererFunc(richFence)
+
+// RichFenceLanguages are the languages that WithRichFences renders with RichFence.
+var RichFenceLanguages = []string{"geojson", "topojson", "stl"}
+
+func richFence(src []byte, lang string) ([]byte, bool) {
+	if (lang == "geojson" || lang == "topojson") && !json.Valid(src) {
+		return nil, false
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<div class="rich-fence" data-type="%s"><pre>`, lang)
+	attrEscape(&buf, src)
+	buf.WriteString(`</pre></div>`)
+	return buf.Bytes(), true
+}
@@ -0,0 +1,46 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestRichFences(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "```geojson\n{\"type\": \"Point\", \"coordinates\": [1, 2]}\n```\n",
+			want: `<div class="rich-fence" data-type="geojson"><pre>{&#34;type&#34;: &#34;Point&#34;, &#34;coordinates&#34;: [1, 2]}` + "\n" + `</pre></div>`,
+		},
+		{
+			text: "```STL\nsolid cube\nendsolid cube\n```\n",
+			want: `<div class="rich-fence" data-type="stl"><pre>solid cube` + "\n" + `endsolid cube` + "\n" + `</pre></div>`,
+		},
+		{
+			// Invalid JSON is rendered as code.
+			text: "```topojson\n{\n```\n",
+			want: `<div class="highlight highlight-topojson"><pre>{` + "\n" + `</pre></div>`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithRichFences())); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestWithFence(t *testing.T) {
+	f := github_flavored_markdown.FenceRendererFunc(func(src []byte, lang string) ([]byte, bool) {
+		return []byte("<p>" + lang + " chart</p>"), true
+	})
+	text := "```Chart\n1 2 3\n```\n"
+	want := "<p>chart chart</p>"
+
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithFence("chart", f))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
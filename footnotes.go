@@ -0,0 +1,177 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/shurcooL/sanitized_anchor_name"
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// footnote is a footnote definition collected from the source.
+type footnote struct {
+	id     string // Sanitized label, used in element ids.
+	text   []byte // Markdown text of the definition.
+	number int    // Display number, 0 until first referenced.
+	refs   int    // Number of references to the footnote.
+}
+
+// footnotes collects footnote definitions and references for a document.
+// It is independent of blackfriday's Footnotes extension.
+type footnotes struct {
+	defs  map[string]*footnote // Keyed by label as written in the source.
+	order []*footnote          // In order of first reference.
+}
+
+var (
+	footnoteDefRE = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:[ \t]*(.*)$`)
+	footnoteRefRE = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+)
+
+// extractFootnotes removes footnote definitions, lines like "[^label]: text"
+// and their indented continuation lines, from text. It returns the remaining
+// text and the collected definitions.
+func extractFootnotes(text []byte) ([]byte, *footnotes) {
+	f := &footnotes{defs: make(map[string]*footnote)}
+	var out [][]byte
+	var fence []byte
+	var last *footnote
+	lines := bytes.Split(text, []byte("\n"))
+	for _, line := range lines {
+		if m := fenceMarker(line); m != nil {
+			switch {
+			case fence == nil:
+				fence = m
+			case bytes.HasPrefix(m, fence):
+				fence = nil
+			}
+		}
+		if fence != nil {
+			last = nil
+			out = append(out, line)
+			continue
+		}
+		if m := footnoteDefRE.FindSubmatch(line); m != nil {
+			label := string(m[1])
+			last = &footnote{id: sanitized_anchor_name.Create(label), text: append([]byte(nil), m[2]...)}
+			if last.id == "" {
+				last.id = strconv.Itoa(len(f.defs) + 1)
+			}
+			if _, ok := f.defs[label]; !ok {
+				f.defs[label] = last
+			}
+			continue
+		}
+		if last != nil && (bytes.HasPrefix(line, []byte("    ")) || bytes.HasPrefix(line, []byte("\t"))) {
+			last.text = append(append(last.text, '\n'), bytes.TrimSpace(line)...)
+			continue
+		}
+		last = nil
+		out = append(out, line)
+	}
+	if len(f.defs) == 0 {
+		return text, f
+	}
+	return bytes.Join(out, []byte("\n")), f
+}
+
+// link replaces references to defined footnotes in the text nodes of doc
+// with superscript links. References to undefined footnotes are left as is.
+func (f *footnotes) link(doc *bf.Node) {
+	if len(f.defs) == 0 {
+		return
+	}
+	var texts []*bf.Node
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if entering && node.Type == bf.Text && footnoteRefRE.Match(node.Literal) {
+			texts = append(texts, node)
+		}
+		return bf.GoToNext
+	})
+
+	for _, node := range texts {
+		parent, next := node.Parent, node.Next
+		literal := node.Literal
+		var prev int
+		for _, m := range footnoteRefRE.FindAllSubmatchIndex(literal, -1) {
+			fn, ok := f.defs[string(literal[m[2]:m[3]])]
+			if !ok {
+				continue
+			}
+			if fn.number == 0 {
+				f.order = append(f.order, fn)
+				fn.number = len(f.order)
+			}
+			fn.refs++
+
+			if prev == 0 {
+				node.Literal = literal[:m[0]]
+			} else {
+				text := bf.NewNode(bf.Text)
+				text.Literal = literal[prev:m[0]]
+				insertBefore(parent, next, text)
+			}
+			ref := bf.NewNode(bf.HTMLSpan)
+			ref.Literal = []byte(fmt.Sprintf(`<sup><a href="#%s" id="%s" data-footnote-ref aria-describedby="footnote-label">%d</a></sup>`, fn.defID(), fn.refID(fn.refs), fn.number))
+			insertBefore(parent, next, ref)
+			prev = m[1]
+		}
+		if prev != 0 && prev < len(literal) {
+			text := bf.NewNode(bf.Text)
+			text.Literal = literal[prev:]
+			insertBefore(parent, next, text)
+		}
+	}
+}
+
+// Element ids have the "user-content-" prefix that GitHub uses for ids
+// from user content, so that they don't clash with ids of the page.
+
+// defID returns the element id of the definition of fn.
+func (fn *footnote) defID() string {
+	return "user-content-fn-" + fn.id
+}
+
+// refID returns the element id of the n-th reference to fn, starting at 1.
+func (fn *footnote) refID(n int) string {
+	if n == 1 {
+		return "user-content-fnref-" + fn.id
+	}
+	return fmt.Sprintf("user-content-fnref-%s-%d", fn.id, n)
+}
+
+// render writes the footnotes section for the referenced footnotes, in order
+// of first reference, with a back link to each reference.
+func (f *footnotes) render(w io.Writer, r *renderer) {
+	if len(f.order) == 0 {
+		return
+	}
+	io.WriteString(w, "\n<section data-footnotes class=\"footnotes\"><h2 id=\"footnote-label\" class=\"sr-only\">Footnotes</h2>\n<ol>\n")
+	for _, fn := range f.order {
+		// Definitions get the transforms of the document, such as URL
+		// rewriting, but can't reference footnotes themselves.
+		doc := r.parseDoc(fn.text, nil)
+		last := doc.LastChild
+		if last == nil || last.Type != bf.Paragraph {
+			last = bf.NewNode(bf.Paragraph)
+			doc.AppendChild(last)
+		}
+		for n := 1; n <= fn.refs; n++ {
+			backref := bf.NewNode(bf.HTMLSpan)
+			if n == 1 {
+				backref.Literal = []byte(fmt.Sprintf(` <a href="#%s" data-footnote-backref aria-label="Back to reference %d" class="data-footnote-backref">↩</a>`, fn.refID(n), fn.number))
+			} else {
+				backref.Literal = []byte(fmt.Sprintf(` <a href="#%s" data-footnote-backref aria-label="Back to reference %d-%d" class="data-footnote-backref">↩<sup>%d</sup></a>`, fn.refID(n), fn.number, n, n))
+			}
+			last.AppendChild(backref)
+		}
+
+		fmt.Fprintf(w, "<li id=\"%s\">\n", fn.defID())
+		r.walk(w, doc)
+		io.WriteString(w, "</li>\n")
+	}
+	io.WriteString(w, "</ol>\n</section>\n")
+}
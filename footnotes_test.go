@@ -0,0 +1,88 @@
+package github_flavored_markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestFootnotes(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			// Single reference.
+			text: "Text[^1].\n\n[^1]: A *note*.\n",
+			want: `<p>Text<sup><a href="#user-content-fn-1" id="user-content-fnref-1" data-footnote-ref="" aria-describedby="footnote-label" rel="nofollow">1</a></sup>.</p>
+
+<section data-footnotes="" class="footnotes"><h2 id="footnote-label" class="sr-only">Footnotes</h2>
+<ol>
+<li id="user-content-fn-1">
+<p>A <em>note</em>. <a href="#user-content-fnref-1" data-footnote-backref="" aria-label="Back to reference 1" class="data-footnote-backref" rel="nofollow">↩</a></p>
+</li>
+</ol>
+</section>
+`,
+		},
+		{
+			// Repeated references, numbered by first reference.
+			text: "A[^b] B[^a] C[^b]\n\n[^a]: Note a.\n[^b]: Note b.\n",
+			want: `<p>A<sup><a href="#user-content-fn-b" id="user-content-fnref-b" data-footnote-ref="" aria-describedby="footnote-label" rel="nofollow">1</a></sup> B<sup><a href="#user-content-fn-a" id="user-content-fnref-a" data-footnote-ref="" aria-describedby="footnote-label" rel="nofollow">2</a></sup> C<sup><a href="#user-content-fn-b" id="user-content-fnref-b-2" data-footnote-ref="" aria-describedby="footnote-label" rel="nofollow">1</a></sup></p>
+
+<section data-footnotes="" class="footnotes"><h2 id="footnote-label" class="sr-only">Footnotes</h2>
+<ol>
+<li id="user-content-fn-b">
+<p>Note b. <a href="#user-content-fnref-b" data-footnote-backref="" aria-label="Back to reference 1" class="data-footnote-backref" rel="nofollow">↩</a> <a href="#user-content-fnref-b-2" data-footnote-backref="" aria-label="Back to reference 1-2" class="data-footnote-backref" rel="nofollow">↩<sup>2</sup></a></p>
+</li>
+<li id="user-content-fn-a">
+<p>Note a. <a href="#user-content-fnref-a" data-footnote-backref="" aria-label="Back to reference 2" class="data-footnote-backref" rel="nofollow">↩</a></p>
+</li>
+</ol>
+</section>
+`,
+		},
+		{
+			// Non-ASCII labels survive sanitization. Links are percent-encoded.
+			text: "Text[^注].\n\n[^注]: Note.\n",
+			want: `<p>Text<sup><a href="#user-content-fn-%E6%B3%A8" id="user-content-fnref-注" data-footnote-ref="" aria-describedby="footnote-label" rel="nofollow">1</a></sup>.</p>
+
+<section data-footnotes="" class="footnotes"><h2 id="footnote-label" class="sr-only">Footnotes</h2>
+<ol>
+<li id="user-content-fn-注">
+<p>Note. <a href="#user-content-fnref-%E6%B3%A8" data-footnote-backref="" aria-label="Back to reference 1" class="data-footnote-backref" rel="nofollow">↩</a></p>
+</li>
+</ol>
+</section>
+`,
+		},
+		{
+			// Undefined footnote, left literal.
+			text: "Text[^missing].",
+			want: `<p>Text[^missing].</p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		opts := github_flavored_markdown.Options{Footnotes: true}
+		if got := string(github_flavored_markdown.MarkdownOptions([]byte(test.text), opts)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestFootnotesRewriteURLs(t *testing.T) {
+	// Definitions get the transforms of the document.
+	text := "Text[^1].\n\n[^1]: See [a](a.md), ![b](http://img.example.net/b.png) and https://example.org.\n"
+	proxy := github_flavored_markdown.ImageProxy{URL: "https://camo.example.com/{hexurl}"}
+	got := string(github_flavored_markdown.Markdown([]byte(text),
+		github_flavored_markdown.WithFootnotes(),
+		github_flavored_markdown.WithBaseURL("https://example.com/docs/"),
+		github_flavored_markdown.WithImageProxy(proxy),
+	))
+	want := `<p>See <a href="https://example.com/docs/a.md" rel="nofollow">a</a>, <img src="https://camo.example.com/687474703a2f2f696d672e6578616d706c652e6e65742f622e706e67" alt="b"/> and <a href="https://example.org" rel="nofollow">https://example.org</a>. <a href="#user-content-fnref-1"`
+	if !strings.Contains(got, want) {
+		t.Errorf("\ngot %q\nwant it to contain %q", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Metadata is the front matter of a document.
+type Metadata struct {
+	Format string                 // "yaml" or "toml".
+	Raw    []byte                 // Front matter without its delimiter lines.
+	Data   map[string]interface{} // Parsed front matter, nil if it's invalid.
+}
+
+// frontMatterDelims maps front matter delimiter lines to their formats.
+var frontMatterDelims = []struct {
+	delim  string
+	format string
+}{
+	{"---", "yaml"},
+	{"+++", "toml"},
+}
+
+// extractFrontMatter removes front matter, YAML between "---" lines or TOML
+// between "+++" lines at the very start of text, and returns the remaining
+// text and the front matter, or nil if there's none. YAML front matter may
+// also end with a "..." line.
+func extractFrontMatter(text []byte) ([]byte, *Metadata) {
+	for _, d := range frontMatterDelims {
+		rest, ok := cutLine(text, d.delim)
+		if !ok {
+			continue
+		}
+		for i := 0; i <= len(rest); {
+			end := bytes.IndexByte(rest[i:], '\n')
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += i
+			}
+			line := bytes.TrimRight(rest[i:end], " \t\r")
+			if string(line) == d.delim || d.format == "yaml" && string(line) == "..." {
+				meta := &Metadata{Format: d.format, Raw: rest[:i]}
+				if end < len(rest) {
+					end++
+				}
+				return rest[end:], meta
+			}
+			i = end + 1
+		}
+	}
+	return text, nil
+}
+
+// cutLine returns text after its first line if that line is delim.
+func cutLine(text []byte, delim string) ([]byte, bool) {
+	end := bytes.IndexByte(text, '\n')
+	if end < 0 || string(bytes.TrimRight(text[:end], " \t\r")) != delim {
+		return nil, false
+	}
+	return text[end+1:], true
+}
+
+// parse parses the raw front matter into m.Data.
+func (m *Metadata) parse() error {
+	data := make(map[string]interface{})
+	var err error
+	switch m.Format {
+	case "yaml":
+		err = yaml.Unmarshal(m.Raw, &data)
+	case "toml":
+		err = toml.Unmarshal(m.Raw, &data)
+	}
+	if err != nil {
+		return err
+	}
+	m.Data = data
+	return nil
+}
+
+// MarkdownMetadata renders GitHub Flavored Markdown text like Markdown with
+// Options.FrontMatter set, and returns the front matter, or nil if text has
+// none. If the front matter can't be parsed, the error is returned along with
+// the rendered output and the raw front matter.
+func MarkdownMetadata(text []byte, opts ...Option) ([]byte, *Metadata, error) {
+	o := newOptions(opts)
+	o.FrontMatter = false
+
+	text, meta := extractFrontMatter(text)
+	var err error
+	if meta != nil {
+		err = meta.parse()
+	}
+
+	return MarkdownOptions(text, o), meta, err
+}
@@ -0,0 +1,74 @@
+package github_flavored_markdown_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestMarkdownMetadata(t *testing.T) {
+	tests := []struct {
+		text     string
+		want     string
+		wantMeta *github_flavored_markdown.Metadata
+	}{
+		{
+			text: "---\ntitle: Hello\ntags: [a, b]\n---\n# Hello\n",
+			want: `<h1><a name="hello" class="anchor" href="#hello" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Hello</h1>` + "\n",
+			wantMeta: &github_flavored_markdown.Metadata{
+				Format: "yaml",
+				Raw:    []byte("title: Hello\ntags: [a, b]\n"),
+				Data:   map[string]interface{}{"title": "Hello", "tags": []interface{}{"a", "b"}},
+			},
+		},
+		{
+			text: "+++\ntitle = \"Hello\"\ndraft = true\n+++\nText.\n",
+			want: "<p>Text.</p>\n",
+			wantMeta: &github_flavored_markdown.Metadata{
+				Format: "toml",
+				Raw:    []byte("title = \"Hello\"\ndraft = true\n"),
+				Data:   map[string]interface{}{"title": "Hello", "draft": true},
+			},
+		},
+		{
+			// A thematic break not at the start isn't front matter.
+			text:     "Text.\n\n---\na: b\n---\n",
+			want:     "<p>Text.</p>\n\n<hr>\n\n<h2><a name=\"a-b\" class=\"anchor\" href=\"#a-b\" rel=\"nofollow\" aria-hidden=\"true\"><span class=\"octicon octicon-link\"></span></a>a: b</h2>\n",
+			wantMeta: nil,
+		},
+	}
+
+	for _, test := range tests {
+		got, meta, err := github_flavored_markdown.MarkdownMetadata([]byte(test.text))
+		if err != nil {
+			t.Errorf("%q: %v", test.text, err)
+		}
+		if string(got) != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+		if !reflect.DeepEqual(meta, test.wantMeta) {
+			t.Errorf("%q: got metadata %+v, want %+v", test.text, meta, test.wantMeta)
+		}
+	}
+}
+
+func TestFrontMatterInvalid(t *testing.T) {
+	text := "---\n: [\n---\nText.\n"
+
+	got, meta, err := github_flavored_markdown.MarkdownMetadata([]byte(text))
+	if err == nil {
+		t.Error("got nil error, want parse error")
+	}
+	if want := "<p>Text.</p>\n"; string(got) != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+	if meta == nil || string(meta.Raw) != ": [\n" || meta.Data != nil {
+		t.Errorf("got metadata %+v, want raw front matter only", meta)
+	}
+
+	// Markdown skips front matter with the option.
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithFrontMatter())); got != "<p>Text.</p>\n" {
+		t.Errorf("\ngot %q\nwant %q", got, "<p>Text.</p>\n")
+	}
+}
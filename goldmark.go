@@ -0,0 +1,175 @@
+//go:build goldmark
+
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	gmrenderer "github.com/yuin/goldmark/renderer"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+func init() {
+	goldmarkRender = renderGoldmark
+}
+
+// renderGoldmark renders text to unsanitized HTML with goldmark, using buf
+// for the output. Headings and code blocks are rendered by r, like those of
+// the Blackfriday engine.
+func renderGoldmark(ctx context.Context, buf *bytes.Buffer, text []byte, opts Options) ([]byte, error) {
+	r := newRenderer(opts)
+	defer r.setContext(ctx)()
+
+	text = truncateInput(text, opts.maxInputSize())
+	if opts.FrontMatter {
+		text, _ = extractFrontMatter(text)
+	}
+
+	extensions := []goldmark.Extender{
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	}
+	if opts.Smartypants {
+		extensions = append(extensions, extension.Typographer)
+	}
+	// The output is sanitized afterwards, like that of Blackfriday.
+	rendererOptions := []gmrenderer.Option{
+		gmhtml.WithUnsafe(),
+		gmrenderer.WithNodeRenderers(util.Prioritized(goldmarkBlocks{r}, 100)),
+	}
+	if opts.Mode == CommentMode {
+		rendererOptions = append(rendererOptions, gmhtml.WithHardWraps())
+	}
+	var parserOptions []parser.Option
+	if rewrite := urlRewriter(opts); rewrite != nil {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(util.Prioritized(goldmarkURLs(rewrite), 100)))
+	}
+	md := goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(rendererOptions...),
+	)
+
+	var w io.Writer = buf
+	if max := opts.MaxOutputSize; max > 0 {
+		r.out = &limitedWriter{w: w, n: max, limit: max}
+		w = r.out
+	}
+	if err := md.Convert(text, w); err != nil {
+		r.err = err
+	}
+	if r.out != nil && r.out.err != nil {
+		r.err = r.out.err
+	}
+	if r.err == nil {
+		// Rendering isn't stopped, but code highlighting is.
+		switch {
+		case ctx.Err() != nil:
+			r.err = ctx.Err()
+		case r.ctx.Err() != nil:
+			r.err = ErrTimeout
+		}
+	}
+	return r.rewrite(buf.Bytes()), r.err
+}
+
+// goldmarkURLs rewrites the destinations of links and images.
+type goldmarkURLs func(dest string, isImage bool) string
+
+func (rewrite goldmarkURLs) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			n.Destination = []byte(rewrite(string(n.Destination), false))
+		case *ast.Image:
+			n.Destination = []byte(rewrite(string(n.Destination), true))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// goldmarkBlocks renders headings and code blocks with the renderer of the
+// Blackfriday engine, so that they get the same anchors and highlighting.
+type goldmarkBlocks struct {
+	r *renderer
+}
+
+func (b goldmarkBlocks) RegisterFuncs(reg gmrenderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHeading, b.heading)
+	reg.Register(ast.KindFencedCodeBlock, b.codeblock)
+	reg.Register(ast.KindCodeBlock, b.codeblock)
+}
+
+func (b goldmarkBlocks) heading(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	node := bf.NewNode(bf.Heading)
+	node.HeadingData.Level = n.(*ast.Heading).Level
+	bf.NewNode(bf.Document).AppendChild(node)
+	if entering {
+		if n.PreviousSibling() != nil {
+			// Separate the heading from the previous block, like Blackfriday.
+			node.InsertBefore(bf.NewNode(bf.Paragraph))
+		}
+		title := bf.NewNode(bf.Text)
+		title.Literal = goldmarkText(n, source)
+		node.AppendChild(title)
+	}
+	b.r.heading(w, node, entering)
+	return ast.WalkContinue, nil
+}
+
+func (b goldmarkBlocks) codeblock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	node := bf.NewNode(bf.CodeBlock)
+	if n, ok := n.(*ast.FencedCodeBlock); ok {
+		node.IsFenced = true
+		if n.Info != nil {
+			node.Info = n.Info.Segment.Value(source)
+		}
+	}
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		node.Literal = append(node.Literal, line.Value(source)...)
+	}
+	bf.NewNode(bf.Document).AppendChild(node)
+	b.r.codeblock(w, node, true)
+	w.WriteString("\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// goldmarkText returns the concatenated text of the descendants of n, like
+// the literals of the text nodes that headingText concatenates.
+func goldmarkText(n ast.Node, source []byte) []byte {
+	var out []byte
+	ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			out = append(out, n.Segment.Value(source)...)
+		case *ast.String:
+			out = append(out, n.Value...)
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return out
+}
@@ -0,0 +1,59 @@
+//go:build goldmark
+
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestGoldmark(t *testing.T) {
+	tests := []struct {
+		text string
+		opts []github_flavored_markdown.Option
+		want string
+	}{
+		{
+			text: "# Hello *world*\n\nText.\n\n## Hello *world*\n",
+			want: `<h1><a name="hello-world" class="anchor" href="#hello-world" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Hello <em>world</em></h1>
+<p>Text.</p>
+
+<h2><a name="hello-world-1" class="anchor" href="#hello-world-1" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Hello <em>world</em></h2>
+`,
+		},
+		{
+			text: "```python\nx = 'a'\n```\n\n    indented\n",
+			want: `<div class="highlight highlight-python"><pre><span class="n">x</span> <span class="p">=</span> <span class="s">&#39;a&#39;</span>
+</pre></div>
+<pre><code>indented
+</code></pre>
+`,
+		},
+		{
+			text: "Hi <script>alert(1)</script><b onclick=\"x()\">bold</b> [a](javascript:x())\n",
+			want: "<p>Hi <b>bold</b> a</p>\n",
+		},
+		{
+			// Blackfriday doesn't end the list at the different bullet.
+			text: "- a\n* b\n",
+			want: "<ul>\n<li>a</li>\n</ul>\n<ul>\n<li>b</li>\n</ul>\n",
+		},
+		{
+			text: "a\nb ~~c~~\n",
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithMode(github_flavored_markdown.CommentMode)},
+			want: "<p>a<br>\nb <del>c</del></p>\n",
+		},
+		{
+			text: "[a](/b) ![c](d.png)\n",
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithBaseURL("https://example.com/x/")},
+			want: `<p><a href="https://example.com/b" rel="nofollow">a</a> <img src="https://example.com/x/d.png" alt="c"></p>` + "\n",
+		},
+	}
+	for _, tc := range tests {
+		opts := append([]github_flavored_markdown.Option{github_flavored_markdown.WithEngine(github_flavored_markdown.Goldmark)}, tc.opts...)
+		if got := string(github_flavored_markdown.Markdown([]byte(tc.text), opts...)); got != tc.want {
+			t.Errorf("%q:\ngot %q\nwant %q", tc.text, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Handler returns an http.Handler that serves the Markdown files in fsys,
+// the files with a .md extension, rendered with opts as complete HTML pages
+// like those of Page. A request for a directory serves its README.md, if it
+// has one, after redirecting to the path with a trailing slash. Other files
+// are served as is, so that the images and files that documents link to are
+// available.
+//
+// Responses have an ETag based on the hash of the Markdown file, and
+// a Last-Modified time from its modification time, if it has one.
+func Handler(fsys fs.FS, opts ...Option) http.Handler {
+	return &handler{
+		fsys:     fsys,
+		opts:     opts,
+		renderer: NewRenderer(opts...),
+		files:    http.FileServer(http.FS(fsys)),
+	}
+}
+
+type handler struct {
+	fsys     fs.FS
+	opts     []Option
+	renderer *Renderer
+	files    http.Handler // Serves the files that aren't Markdown.
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+req.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+	if fi, err := fs.Stat(h.fsys, name); err == nil && fi.IsDir() {
+		if !strings.HasSuffix(req.URL.Path, "/") {
+			// Redirect like http.FileServer, so that relative links in the
+			// README resolve within the directory.
+			localRedirect(w, req, path.Base(req.URL.Path)+"/")
+			return
+		}
+		readme := path.Join(name, "README.md")
+		if _, err := fs.Stat(h.fsys, readme); err != nil {
+			h.files.ServeHTTP(w, req)
+			return
+		}
+		name = readme
+	}
+	if path.Ext(name) != ".md" {
+		h.files.ServeHTTP(w, req)
+		return
+	}
+
+	fi, err := fs.Stat(h.fsys, name)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	text, err := fs.ReadFile(h.fsys, name)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	sum := sha256.Sum256(text)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	http.ServeContent(w, req, name, fi.ModTime(), bytes.NewReader(h.page(name, text)))
+}
+
+// page returns text, the contents of the file name, rendered as a complete
+// HTML page.
+func (h *handler) page(name string, text []byte) []byte {
+	title, ok := Title(text, h.opts...)
+	if !ok {
+		title = path.Base(name)
+	}
+	return Page(title, h.renderer.Render(text))
+}
+
+// localRedirect redirects to target, relative to the request URL, keeping
+// its query.
+func localRedirect(w http.ResponseWriter, req *http.Request, target string) {
+	if q := req.URL.RawQuery; q != "" {
+		target += "?" + q
+	}
+	w.Header().Set("Location", target)
+	w.WriteHeader(http.StatusMovedPermanently)
+}
+
+// httpError writes an error response for err, an error from fsys.
+func httpError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+	default:
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,107 @@
+package github_flavored_markdown_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestHandler(t *testing.T) {
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"README.md":      {Data: []byte("# Project\n\nSee the [docs](docs/guide.md).\n"), ModTime: modTime},
+		"docs/guide.md":  {Data: []byte("Hello <script>alert();</script> **world**.\n"), ModTime: modTime},
+		"docs/image.txt": {Data: []byte("not markdown")},
+		"docs/README.md": {Data: []byte("![Logo](logo.png)\n")},
+		"docs/logo.png":  {Data: []byte("png")},
+	}
+	h := github_flavored_markdown.Handler(fsys)
+
+	tests := []struct {
+		path         string
+		wantStatus   int
+		wantType     string
+		wantBody     []string
+		wantLocation string
+	}{
+		{
+			path:       "/",
+			wantStatus: http.StatusOK,
+			wantType:   "text/html; charset=utf-8",
+			wantBody:   []string{"<title>Project</title>", ".markdown-body", `<a href="docs/guide.md" rel="nofollow">docs</a>`},
+		},
+		{
+			path:       "/docs/guide.md",
+			wantStatus: http.StatusOK,
+			wantType:   "text/html; charset=utf-8",
+			wantBody:   []string{"<title>guide.md</title>", "<p>Hello  <strong>world</strong>.</p>"},
+		},
+		{
+			path:       "/docs/image.txt",
+			wantStatus: http.StatusOK,
+			wantType:   "text/plain; charset=utf-8",
+			wantBody:   []string{"not markdown"},
+		},
+		{
+			// Relative images in a README resolve within its directory.
+			path:         "/docs",
+			wantStatus:   http.StatusMovedPermanently,
+			wantLocation: "docs/",
+		},
+		{
+			path:       "/docs/",
+			wantStatus: http.StatusOK,
+			wantType:   "text/html; charset=utf-8",
+			wantBody:   []string{`<img src="logo.png" alt="Logo"/>`},
+		},
+		{
+			path:       "/missing.md",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", test.path, nil))
+		if rec.Code != test.wantStatus {
+			t.Errorf("%s: got status %d, want %d", test.path, rec.Code, test.wantStatus)
+			continue
+		}
+		if got := rec.Header().Get("Location"); got != test.wantLocation {
+			t.Errorf("%s: got Location %q, want %q", test.path, got, test.wantLocation)
+		}
+		if test.wantType != "" {
+			if got := rec.Header().Get("Content-Type"); got != test.wantType {
+				t.Errorf("%s: got Content-Type %q, want %q", test.path, got, test.wantType)
+			}
+		}
+		for _, want := range test.wantBody {
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("%s: body doesn't contain %q:\n%s", test.path, want, rec.Body.String())
+			}
+		}
+	}
+
+	// Conditional requests.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/docs/guide.md", nil))
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if got, want := rec.Header().Get("Last-Modified"), modTime.Format(http.TimeFormat); got != want {
+		t.Errorf("got Last-Modified %q, want %q", got, want)
+	}
+	req := httptest.NewRequest("GET", "/docs/guide.md", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotModified)
+	}
+}
@@ -0,0 +1,135 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestCopyableHeadingAnchors(t *testing.T) {
+	text := "## Getting Started"
+	want := `<h2><button class="copy-anchor" data-anchor="#getting-started" aria-label="Copy link"><span class="octicon octicon-link"></span></button>Getting Started</h2>` + "\n"
+
+	opts := github_flavored_markdown.Options{CopyableHeadingAnchors: true}
+	if got := string(github_flavored_markdown.MarkdownOptions([]byte(text), opts)); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	// Without the option, buttons in raw HTML are removed.
+	text = `<button class="copy-anchor" data-anchor="#a" aria-label="Copy link">A</button>`
+	want = "<p>A</p>\n"
+	if got := string(github_flavored_markdown.Markdown([]byte(text))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestHeadingIDs(t *testing.T) {
+	tests := []struct {
+		text string
+		opts []github_flavored_markdown.Option
+		want string
+	}{
+		{
+			text: "## Getting Started",
+			want: `<h2 id="getting-started"><a class="anchor" href="#getting-started" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Getting Started</h2>` + "\n",
+		},
+		{
+			text: "## Привет",
+			want: `<h2 id="привет"><a class="anchor" href="#%D0%BF%D1%80%D0%B8%D0%B2%D0%B5%D1%82" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Привет</h2>` + "\n",
+		},
+		{
+			text: "## Getting Started",
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithGitHubAnchors()},
+			want: `<h2 id="user-content-getting-started"><a class="anchor" href="#getting-started" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Getting Started</h2>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		opts := append([]github_flavored_markdown.Option{github_flavored_markdown.WithHeadingIDs()}, test.opts...)
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), opts...)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestHeadingLevelOffset(t *testing.T) {
+	tests := []struct {
+		text   string
+		offset int
+		want   string
+	}{
+		{
+			text:   "# Title",
+			offset: 1,
+			want:   `<h2><a name="title" class="anchor" href="#title" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Title</h2>` + "\n",
+		},
+		{
+			// Levels are clamped to h6.
+			text:   "##### Title",
+			offset: 2,
+			want:   `<h6><a name="title" class="anchor" href="#title" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Title</h6>` + "\n",
+		},
+		{
+			text:   "## Title",
+			offset: -3,
+			want:   `<h1><a name="title" class="anchor" href="#title" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Title</h1>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.HeadingLevelOffset(test.offset))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestWithoutHeadingAnchors(t *testing.T) {
+	tests := []struct {
+		text string
+		opts []github_flavored_markdown.Option
+		want string
+	}{
+		{
+			text: "## Getting *Started*",
+			want: `<h2>Getting <em>Started</em></h2>` + "\n",
+		},
+		{
+			text: "## Getting Started",
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithHeadingIDs()},
+			want: `<h2 id="getting-started">Getting Started</h2>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		opts := append([]github_flavored_markdown.Option{github_flavored_markdown.WithoutHeadingAnchors()}, test.opts...)
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), opts...)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestHeadingInlineFormatting(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "## **Bold** title",
+			want: `<h2><a name="bold-title" class="anchor" href="#bold-title" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a><strong>Bold</strong> title</h2>` + "\n",
+		},
+		{
+			text: "## Use `go get` with [links](https://example.com) and _emphasis_",
+			want: `<h2><a name="use-go-get-with-links-and-emphasis" class="anchor" href="#use-go-get-with-links-and-emphasis" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Use <code>go get</code> with <a href="https://example.com" rel="nofollow">links</a> and <em>emphasis</em></h2>` + "\n",
+		},
+		{
+			text: "Setext *title*\n---\n",
+			want: `<h2><a name="setext-title" class="anchor" href="#setext-title" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>Setext <em>title</em></h2>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package github_flavored_markdown
+
+import (
+	"runtime"
+	"sync"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// Highlighter highlights source code of fenced code blocks.
+type Highlighter interface {
+	// Highlight returns src in language lang as highlighted HTML.
+	// The output must be HTML escaped. It reports false if it can't
+	// highlight lang, in which case the built-in highlighters are used.
+	// It's called for one code block at a time.
+	Highlight(src []byte, lang string) ([]byte, bool)
+}
+
+// HighlighterFunc is an adapter to allow the use of ordinary functions as Highlighters.
+type HighlighterFunc func(src []byte, lang string) ([]byte, bool)
+
+// Highlight calls f(src, lang).
+func (f HighlighterFunc) Highlight(src []byte, lang string) ([]byte, bool) {
+	return f(src, lang)
+}
+
+// highlightResult is the result of highlighting a code block.
+type highlightResult struct {
+	code []byte
+	ok   bool
+}
+
+// highlightAll highlights the code blocks of doc and stores the results in
+// r.highlighted for codeblock. Options.Highlighter, which needn't be safe
+// for concurrent use, is called for one block at a time. The built-in
+// highlighters highlight the blocks it declines concurrently, using up to
+// GOMAXPROCS goroutines. It stops starting to highlight blocks once r.ctx
+// is done; codeblock highlights the blocks without a result itself.
+func (r *renderer) highlightAll(doc *bf.Node) {
+	var blocks []*bf.Node
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if node.Type == bf.CodeBlock && r.highlights(node) {
+			blocks = append(blocks, node)
+		}
+		return bf.GoToNext
+	})
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+	if workers < 2 {
+		return
+	}
+
+	results := make([]*highlightResult, len(blocks))
+	var builtin []int // Indexes of the blocks for the built-in highlighters.
+	for i, node := range blocks {
+		switch {
+		case !r.opts.highlightable(node.Literal):
+			results[i] = &highlightResult{}
+		case r.opts.Highlighter == nil:
+			builtin = append(builtin, i)
+		case r.ctx.Err() == nil:
+			if code, ok := r.opts.Highlighter.Highlight(node.Literal, string(r.codeLang(node))); ok {
+				results[i] = &highlightResult{code: code, ok: true}
+			} else {
+				builtin = append(builtin, i)
+			}
+		}
+	}
+	if workers > len(builtin) {
+		workers = len(builtin)
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if r.ctx.Err() != nil {
+					continue
+				}
+				code, ok := r.highlightBuiltin(blocks[i].Literal, string(r.codeLang(blocks[i])))
+				results[i] = &highlightResult{code: code, ok: ok}
+			}
+		}()
+	}
+	for _, i := range builtin {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	r.highlighted = make(map[*bf.Node]*highlightResult, len(blocks))
+	for i, result := range results {
+		if result != nil {
+			r.highlighted[blocks[i]] = result
+		}
+	}
+}
+
+// highlights reports whether codeblock highlights the code block node,
+// rather than rendering it otherwise.
+func (r *renderer) highlights(node *bf.Node) bool {
+	lang := string(r.codeLang(node))
+	if (r.opts.Math && lang == "math") || (r.opts.Mermaid && lang == "mermaid") {
+		return false
+	}
+	_, ok := r.opts.Fences[lang]
+	return !ok
+}
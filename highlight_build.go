@@ -0,0 +1,214 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// makeDirectives are the GNU make directives highlighted as keywords.
+var makeDirectives = map[string]bool{
+	"include": true, "-include": true, "sinclude": true,
+	"ifeq": true, "ifneq": true, "ifdef": true, "ifndef": true, "else": true, "endif": true,
+	"define": true, "endef": true, "export": true, "unexport": true, "override": true, "vpath": true,
+}
+
+// highlightMakefile highlights Makefile source. It recognizes comments, directives,
+// rule targets and variable references. Recipe lines (those starting with a tab)
+// are shell commands, so only variable references are highlighted in them.
+func highlightMakefile(src []byte) []byte {
+	var buf bytes.Buffer
+	lines := strings.SplitAfter(string(src), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "\t") {
+			writeMakeText(&buf, line, false)
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(trimmed)]
+		template.HTMLEscape(&buf, []byte(indent))
+
+		if strings.HasPrefix(trimmed, "#") {
+			writeSpan(&buf, gfmHTMLConfig.Comment, strings.TrimSuffix(trimmed, "\n"))
+			if strings.HasSuffix(trimmed, "\n") {
+				buf.WriteByte('\n')
+			}
+			continue
+		}
+
+		word := trimmed
+		if i := strings.IndexAny(word, " \t\n"); i >= 0 {
+			word = word[:i]
+		}
+		if makeDirectives[word] {
+			writeSpan(&buf, gfmHTMLConfig.Keyword, word)
+			writeMakeText(&buf, trimmed[len(word):], true)
+			continue
+		}
+
+		if i := makeTargetEnd(trimmed); i > 0 {
+			writeSpan(&buf, gfmHTMLConfig.Type, trimmed[:i])
+			writeMakeText(&buf, trimmed[i:], true)
+			continue
+		}
+
+		writeMakeText(&buf, trimmed, true)
+	}
+	return buf.Bytes()
+}
+
+// makeTargetEnd returns the length of the rule target list at the start of line,
+// or 0 if line is not a rule. Variable assignments such as "A := b" are not rules.
+func makeTargetEnd(line string) int {
+	i := strings.IndexByte(line, ':')
+	if i <= 0 || strings.ContainsAny(line[:i], "=#$") {
+		return 0
+	}
+	if strings.HasPrefix(line[i:], ":=") || strings.HasPrefix(line[i:], "::=") {
+		return 0
+	}
+	return i
+}
+
+// writeMakeText writes s, highlighting variable references. If comments is true,
+// an unescaped "#" starts a comment that runs to the end of the line.
+func writeMakeText(buf *bytes.Buffer, s string, comments bool) {
+	for len(s) > 0 {
+		switch {
+		case comments && s[0] == '#':
+			end := strings.IndexByte(s, '\n')
+			if end < 0 {
+				end = len(s)
+			}
+			writeSpan(buf, gfmHTMLConfig.Comment, s[:end])
+			s = s[end:]
+		case s[0] == '\\' && len(s) > 1:
+			template.HTMLEscape(buf, []byte(s[:2]))
+			s = s[2:]
+		case s[0] == '$' && len(s) > 1:
+			n := variableRefEnd(s)
+			writeSpan(buf, gfmHTMLConfig.Literal, s[:n])
+			s = s[n:]
+		default:
+			n := strings.IndexAny(s[1:], "#\\$")
+			if n < 0 {
+				n = len(s)
+			} else {
+				n++
+			}
+			template.HTMLEscape(buf, []byte(s[:n]))
+			s = s[n:]
+		}
+	}
+}
+
+// variableRefEnd returns the length of the variable reference at the start of s,
+// which begins with "$". It handles "$(VAR)", "${VAR}" with nesting, and
+// single character references such as "$@". An unterminated reference
+// extends to the end of the line.
+func variableRefEnd(s string) int {
+	var open, close byte
+	switch s[1] {
+	case '(':
+		open, close = '(', ')'
+	case '{':
+		open, close = '{', '}'
+	default:
+		return 2
+	}
+	depth := 0
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '\n':
+			return i
+		}
+	}
+	return len(s)
+}
+
+// highlightCMake highlights CMake source. It recognizes comments, quoted arguments,
+// variable references and command invocations such as "add_library(".
+func highlightCMake(src []byte) []byte {
+	var buf bytes.Buffer
+	s := string(src)
+	lineStart := true
+	for len(s) > 0 {
+		switch c := s[0]; {
+		case c == '\n':
+			buf.WriteByte('\n')
+			s = s[1:]
+			lineStart = true
+			continue
+		case c == ' ' || c == '\t':
+			buf.WriteByte(c)
+			s = s[1:]
+			continue
+		case c == '#':
+			end := strings.IndexByte(s, '\n')
+			if end < 0 {
+				end = len(s)
+			}
+			writeSpan(&buf, gfmHTMLConfig.Comment, s[:end])
+			s = s[end:]
+		case c == '"':
+			end := len(s)
+			for i := 1; i < len(s); i++ {
+				if s[i] == '\\' {
+					i++
+				} else if s[i] == '"' {
+					end = i + 1
+					break
+				}
+			}
+			writeSpan(&buf, gfmHTMLConfig.String, s[:end])
+			s = s[end:]
+		case c == '$' && len(s) > 1 && s[1] == '{':
+			n := variableRefEnd(s)
+			writeSpan(&buf, gfmHTMLConfig.Literal, s[:n])
+			s = s[n:]
+		case lineStart && isIdentByte(c):
+			n := 1
+			for n < len(s) && isIdentByte(s[n]) {
+				n++
+			}
+			if rest := strings.TrimLeft(s[n:], " \t"); strings.HasPrefix(rest, "(") {
+				writeSpan(&buf, gfmHTMLConfig.Keyword, s[:n])
+			} else {
+				template.HTMLEscape(&buf, []byte(s[:n]))
+			}
+			s = s[n:]
+		default:
+			n := strings.IndexAny(s[1:], "\n \t#\"$")
+			if n < 0 {
+				n = len(s)
+			} else {
+				n++
+			}
+			template.HTMLEscape(&buf, []byte(s[:n]))
+			s = s[n:]
+		}
+		lineStart = false
+	}
+	return buf.Bytes()
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// writeSpan writes text, HTML escaped, wrapped in a span with the given class.
+func writeSpan(buf *bytes.Buffer, class, text string) {
+	buf.WriteString(`<span class="`)
+	buf.WriteString(class)
+	buf.WriteString(`">`)
+	template.HTMLEscape(buf, []byte(text))
+	buf.WriteString(`</span>`)
+}
@@ -0,0 +1,26 @@
+package github_flavored_markdown
+
+import "testing"
+
+func TestHighlightMakefile(t *testing.T) {
+	src := "# Build it.\nCC := gcc\n\nall: main.o\n\t$(CC) -o app main.o # link\n"
+	want := `<span class="c"># Build it.</span>` + "\n" +
+		`CC := gcc` + "\n" +
+		"\n" +
+		`<span class="n">all</span>: main.o` + "\n" +
+		"\t" + `<span class="o">$(CC)</span> -o app main.o # link` + "\n"
+
+	if got := string(highlightMakefile([]byte(src))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestHighlightCMake(t *testing.T) {
+	src := "# Library.\nadd_library(foo ${SOURCES} \"a b.c\")\n"
+	want := `<span class="c"># Library.</span>` + "\n" +
+		`<span class="k">add_library</span>(foo <span class="o">${SOURCES}</span> <span class="s">&#34;a b.c&#34;</span>)` + "\n"
+
+	if got := string(highlightCMake([]byte(src))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
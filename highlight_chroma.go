@@ -0,0 +1,60 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// highlightChroma highlights src using the Chroma lexer for lang, if there is one.
+// Tokens are wrapped in spans with the same classes as the other highlighters.
+func highlightChroma(src []byte, lang string) ([]byte, bool) {
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		return nil, false
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, string(src))
+	if err != nil {
+		return nil, false
+	}
+	var buf bytes.Buffer
+	for t := it(); t != chroma.EOF; t = it() {
+		class := chromaClass(t.Type)
+		if class == "" {
+			template.HTMLEscape(&buf, []byte(t.Value))
+			continue
+		}
+		writeSpan(&buf, class, t.Value)
+	}
+	return buf.Bytes(), true
+}
+
+// chromaClass returns the class for tokens of type t, or "" for unstyled text.
+func chromaClass(t chroma.TokenType) string {
+	switch {
+	case t.InCategory(chroma.Comment):
+		return gfmHTMLConfig.Comment
+	case t == chroma.KeywordType:
+		return gfmHTMLConfig.Type
+	case t.InCategory(chroma.Keyword):
+		return gfmHTMLConfig.Keyword
+	case t.InSubCategory(chroma.LiteralString):
+		return gfmHTMLConfig.String
+	case t.InSubCategory(chroma.LiteralNumber):
+		return gfmHTMLConfig.Decimal
+	case t.InCategory(chroma.Literal):
+		return gfmHTMLConfig.Literal
+	case t == chroma.NameTag:
+		return gfmHTMLConfig.HTMLTag
+	case t == chroma.NameAttribute:
+		return gfmHTMLConfig.HTMLAttrName
+	case t.InCategory(chroma.Name):
+		return gfmHTMLConfig.Plaintext
+	case t.InCategory(chroma.Operator), t.InCategory(chroma.Punctuation):
+		return gfmHTMLConfig.Punctuation
+	default:
+		return ""
+	}
+}
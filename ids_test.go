@@ -0,0 +1,29 @@
+package github_flavored_markdown_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestRandSeed(t *testing.T) {
+	// Renders with the same seed have the same generated ids.
+	text := []byte("# Title\n\n## Title\n\nText[^1].\n\n[^1]: Note.\n")
+	render := func(seed int64) []byte {
+		return github_flavored_markdown.Markdown(text,
+			github_flavored_markdown.WithRandSeed(seed),
+			github_flavored_markdown.WithHeadingIDs(),
+			github_flavored_markdown.WithFootnotes(),
+		)
+	}
+	out := render(42)
+	if !bytes.Contains(out, []byte(`id="title-1"`)) || !bytes.Contains(out, []byte(`id="user-content-fn-1"`)) {
+		t.Fatalf("no generated ids in the output:\n%s", out)
+	}
+	for i := 0; i < 3; i++ {
+		if got := render(42); !bytes.Equal(got, out) {
+			t.Errorf("same seed, different output:\n%s\n%s", got, out)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package github_flavored_markdown
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DefaultIframeSandbox are the sandbox tokens of iframes embedded with
+// Options.IframeOrigins when Options.IframeSandbox is nil. They allow
+// typical video players to work.
+var DefaultIframeSandbox = []string{"allow-popups", "allow-presentation", "allow-same-origin", "allow-scripts"}
+
+// sandboxValues maps iframe sandbox tokens to their bluemonday values.
+var sandboxValues = map[string]bluemonday.SandboxValue{
+	"allow-downloads":                         bluemonday.SandboxAllowDownloads,
+	"allow-downloads-without-user-activation": bluemonday.SandboxAllowDownloadsWithoutUserActivation,
+	"allow-forms":                             bluemonday.SandboxAllowForms,
+	"allow-modals":                            bluemonday.SandboxAllowModals,
+	"allow-orientation-lock":                  bluemonday.SandboxAllowOrientationLock,
+	"allow-pointer-lock":                      bluemonday.SandboxAllowPointerLock,
+	"allow-popups":                            bluemonday.SandboxAllowPopups,
+	"allow-popups-to-escape-sandbox":          bluemonday.SandboxAllowPopupsToEscapeSandbox,
+	"allow-presentation":                      bluemonday.SandboxAllowPresentation,
+	"allow-same-origin":                       bluemonday.SandboxAllowSameOrigin,
+	"allow-scripts":                           bluemonday.SandboxAllowScripts,
+	"allow-storage-access-by-user-activation": bluemonday.SandboxAllowStorageAccessByUserActivation,
+	"allow-top-navigation":                    bluemonday.SandboxAllowTopNavigation,
+	"allow-top-navigation-by-user-activation": bluemonday.SandboxAllowTopNavigationByUserActivation,
+}
+
+// iframeFeatures are the features that the allow attribute of embedded
+// iframes may list.
+const iframeFeatures = `accelerometer|autoplay|clipboard-write|encrypted-media|fullscreen|gyroscope|picture-in-picture|web-share`
+
+// iframeRE matches the start of an iframe element.
+var iframeRE = regexp.MustCompile(`(?i)<iframe[\t\n\f\r />]`)
+
+// iframeSandbox returns the known sandbox tokens of opts.IframeSandbox,
+// or DefaultIframeSandbox, sorted.
+func (opts Options) iframeSandbox() []string {
+	tokens := opts.IframeSandbox
+	if tokens == nil {
+		tokens = DefaultIframeSandbox
+	}
+	var sandbox []string
+	for _, t := range tokens {
+		if _, ok := sandboxValues[t]; ok {
+			sandbox = append(sandbox, t)
+		}
+	}
+	sort.Strings(sandbox)
+	return sandbox
+}
+
+// iframeOrigins returns the normalized, sorted opts.IframeOrigins.
+func iframeOrigins(opts Options) []string {
+	var origins []string
+	for _, o := range opts.IframeOrigins {
+		origins = append(origins, strings.ToLower(strings.TrimSuffix(o, "/")))
+	}
+	sort.Strings(origins)
+	return origins
+}
+
+// iframeSrcRE returns a regexp that matches URLs with one of origins.
+func iframeSrcRE(origins []string) *regexp.Regexp {
+	quoted := make([]string, len(origins))
+	for i, o := range origins {
+		quoted[i] = regexp.QuoteMeta(o)
+	}
+	return regexp.MustCompile(`(?i)^(` + strings.Join(quoted, "|") + `)([/?#]|$)`)
+}
+
+// allowIframes extends p to allow iframes that embed origins, with at most
+// the sandbox tokens sandbox.
+func allowIframes(p *bluemonday.Policy, origins, sandbox []string) {
+	p.AllowAttrs("src").Matching(iframeSrcRE(origins)).OnElements("iframe")
+	p.AllowAttrs("width", "height").Matching(regexp.MustCompile(`^[0-9]+%?$`)).OnElements("iframe")
+	p.AllowAttrs("title").OnElements("iframe")
+	p.AllowAttrs("frameborder").Matching(regexp.MustCompile(`^[01]$`)).OnElements("iframe")
+	p.AllowAttrs("allowfullscreen").Matching(regexp.MustCompile(`(?i)^(|true|allowfullscreen)$`)).OnElements("iframe")
+	p.AllowAttrs("loading").Matching(regexp.MustCompile(`^(lazy|eager)$`)).OnElements("iframe")
+	p.AllowAttrs("referrerpolicy").Matching(regexp.MustCompile(`^[a-z-]+$`)).OnElements("iframe")
+	p.AllowAttrs("allow").Matching(regexp.MustCompile(`^(` + iframeFeatures + `)(; ?(` + iframeFeatures + `))*;?$`)).OnElements("iframe")
+	p.AllowAttrs("sandbox").OnElements("iframe")
+
+	values := make([]bluemonday.SandboxValue, len(sandbox))
+	for i, t := range sandbox {
+		values[i] = sandboxValues[t]
+	}
+	p.RequireSandboxOnIFrame(values...)
+}
+
+// embedIframes returns a function that removes the iframes within an HTML
+// node that don't embed one of origins, and gives those that have no
+// sandbox attribute the sandbox tokens sandbox.
+func embedIframes(origins, sandbox []string) func(n *html.Node) {
+	src := iframeSrcRE(origins)
+	var f func(n *html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == html.ElementNode && c.DataAtom == atom.Iframe && !src.MatchString(attrValue(c, "src")) {
+				n.RemoveChild(c)
+			} else {
+				f(c)
+			}
+			c = next
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Iframe && !hasAttr(n, "sandbox") {
+			n.Attr = append(n.Attr, html.Attribute{Key: "sandbox", Val: strings.Join(sandbox, " ")})
+		}
+	}
+	return f
+}
+
+// attrValue returns the value of the attribute key of n, or "" if n has none.
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether n has the attribute key.
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestWithIframeOrigins(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: `<iframe width="560" height="315" src="https://www.youtube.com/embed/abc" title="Video" frameborder="0" allow="accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture; web-share" allowfullscreen></iframe>` + "\n",
+			want: `<iframe width="560" height="315" src="https://www.youtube.com/embed/abc" title="Video" frameborder="0" allow="accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture; web-share" allowfullscreen="" sandbox="allow-popups allow-presentation allow-same-origin allow-scripts"></iframe>` + "\n",
+		},
+		{
+			// Sandbox tokens and features that aren't allowed are removed.
+			text: `<iframe src="https://player.vimeo.com/video/1" sandbox="allow-scripts allow-top-navigation" allow="camera; microphone" onload="f()"></iframe>` + "\n",
+			want: `<iframe src="https://player.vimeo.com/video/1" sandbox="allow-scripts"></iframe>` + "\n",
+		},
+		{
+			// Other origins are removed, including look-alike hosts.
+			text: `<iframe src="https://evil.example.com/"></iframe><iframe src="https://www.youtube.com.evil.example.com/embed/abc"></iframe>` + "\n",
+			want: "\n",
+		},
+	}
+
+	for _, test := range tests {
+		got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithIframeOrigins("https://www.youtube.com", "https://player.vimeo.com/")))
+		if got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+
+	// Without the option, iframes are removed.
+	text := `<iframe src="https://www.youtube.com/embed/abc"></iframe>` + "\n"
+	if got := string(github_flavored_markdown.Markdown([]byte(text))); got != "\n" {
+		t.Errorf("\ngot %q\nwant %q", got, "\n")
+	}
+}
@@ -0,0 +1,122 @@
+package github_flavored_markdown
+
+import (
+	"regexp"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// replaceText calls f with the literal of each text node in doc, and replaces
+// the node with the nodes f returns, unless it returns nil. Code spans and
+// blocks are not text nodes, so they are never changed.
+func replaceText(doc *bf.Node, f func(text []byte) []*bf.Node) {
+	replaceTexts(collectText(doc, false), f)
+}
+
+// replaceUnlinkedText is like replaceText, but skips the text of links.
+// It is used by passes that add links, which must not be nested.
+func replaceUnlinkedText(doc *bf.Node, f func(text []byte) []*bf.Node) {
+	replaceTexts(collectText(doc, true), f)
+}
+
+// collectText returns the text nodes of doc, optionally skipping those in links.
+func collectText(doc *bf.Node, skipLinks bool) []*bf.Node {
+	var texts []*bf.Node
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering {
+			return bf.GoToNext
+		}
+		switch {
+		case skipLinks && node.Type == bf.Link:
+			return bf.SkipChildren
+		case node.Type == bf.Text:
+			texts = append(texts, node)
+		}
+		return bf.GoToNext
+	})
+	return texts
+}
+
+func replaceTexts(texts []*bf.Node, f func(text []byte) []*bf.Node) {
+	for _, node := range texts {
+		nodes := f(node.Literal)
+		if nodes == nil {
+			continue
+		}
+		for _, n := range nodes {
+			node.InsertBefore(n)
+		}
+		node.Unlink()
+	}
+}
+
+// replaceMatches returns the nodes that text with matches of re replaced
+// consists of, or nil if nothing was replaced. The part of each match in
+// the first subexpression of re is replaced with the HTML f returns for the
+// match's submatches, unless f returns false. The rest of the match, such as
+// a preceding delimiter that re requires, is kept as text.
+func replaceMatches(text []byte, re *regexp.Regexp, f func(m [][]byte) (string, bool)) []*bf.Node {
+	var nodes []*bf.Node
+	var prev int
+	for _, loc := range re.FindAllSubmatchIndex(text, -1) {
+		m := make([][]byte, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		html, ok := f(m)
+		if !ok {
+			continue
+		}
+		if start := loc[2]; start > prev {
+			nodes = append(nodes, newText(text[prev:start]))
+		}
+		nodes = append(nodes, newHTMLSpan(html))
+		prev = loc[3]
+	}
+	if nodes == nil {
+		return nil
+	}
+	if prev < len(text) {
+		nodes = append(nodes, newText(text[prev:]))
+	}
+	return nodes
+}
+
+// newText returns a text node with literal text.
+func newText(text []byte) *bf.Node {
+	n := bf.NewNode(bf.Text)
+	n.Literal = text
+	return n
+}
+
+// newHTMLSpan returns an inline HTML node with literal html.
+func newHTMLSpan(html string) *bf.Node {
+	n := bf.NewNode(bf.HTMLSpan)
+	n.Literal = []byte(html)
+	return n
+}
+
+// newLink returns a link node to destination with literal text.
+func newLink(destination, text []byte) *bf.Node {
+	n := bf.NewNode(bf.Link)
+	n.LinkData.Destination = destination
+	n.AppendChild(newText(text))
+	return n
+}
+
+// trimTrailingBreaks removes the line breaks that end paragraphs in doc,
+// which blackfriday adds for the final newline of list items when newlines
+// are line breaks.
+func trimTrailingBreaks(doc *bf.Node) {
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if entering && node.Type == bf.Paragraph {
+			for node.LastChild != nil && node.LastChild.Type == bf.Hardbreak {
+				node.LastChild.Unlink()
+			}
+			return bf.SkipChildren
+		}
+		return bf.GoToNext
+	})
+}
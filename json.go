@@ -0,0 +1,76 @@
+package github_flavored_markdown
+
+import (
+	"encoding/json"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// jsonNode is the JSON representation of a document node.
+type jsonNode struct {
+	Type        string      `json:"type"`
+	Literal     string      `json:"literal,omitempty"`
+	Level       int         `json:"level,omitempty"`       // Heading level.
+	Destination string      `json:"destination,omitempty"` // Link or image URL.
+	Title       string      `json:"title,omitempty"`       // Link or image title.
+	Info        string      `json:"info,omitempty"`        // Code block info string.
+	Ordered     bool        `json:"ordered,omitempty"`     // Ordered list.
+	Header      bool        `json:"header,omitempty"`      // Table header cell.
+	Align       string      `json:"align,omitempty"`       // Table cell alignment.
+	Start       *int        `json:"start,omitempty"`       // Byte offset of the source, if known.
+	End         *int        `json:"end,omitempty"`         // Byte offset of the end of the source, if known.
+	Children    []*jsonNode `json:"children,omitempty"`
+}
+
+// MarshalJSON returns the document tree as JSON. Each node is an object with
+// its "type", such as "Paragraph", its "literal" text, if any, other data
+// depending on its type, and its "children". blackfriday doesn't record the
+// source positions of nodes, so only headings have "start" and "end" byte
+// offsets, when they can be determined like by Outline.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	var headings []*jsonNode
+	root := toJSONNode(d.Node, &headings)
+	if spans := sourceHeadingSpans(d.text, d.opts, len(headings)); spans != nil {
+		for i, h := range headings {
+			h.Start, h.End = &spans[i][0], &spans[i][1]
+		}
+	}
+	return json.Marshal(root)
+}
+
+// MarkdownJSON parses GitHub Flavored Markdown text like Parse, and returns
+// the document tree as JSON. See Document.MarshalJSON.
+func MarkdownJSON(text []byte, opts ...Option) ([]byte, error) {
+	return Parse(text, opts...).MarshalJSON()
+}
+
+// toJSONNode converts node and its children, appending headings to headings.
+func toJSONNode(node *bf.Node, headings *[]*jsonNode) *jsonNode {
+	n := &jsonNode{Type: node.Type.String(), Literal: string(node.Literal)}
+	switch node.Type {
+	case bf.Heading:
+		n.Level = node.HeadingData.Level
+		*headings = append(*headings, n)
+	case bf.Link, bf.Image:
+		n.Destination = string(node.LinkData.Destination)
+		n.Title = string(node.LinkData.Title)
+	case bf.CodeBlock:
+		n.Info = string(node.Info)
+	case bf.List:
+		n.Ordered = node.ListData.ListFlags&bf.ListTypeOrdered != 0
+	case bf.TableCell:
+		n.Header = node.TableCellData.IsHeader
+		switch node.TableCellData.Align {
+		case bf.TableAlignmentLeft:
+			n.Align = "left"
+		case bf.TableAlignmentRight:
+			n.Align = "right"
+		case bf.TableAlignmentCenter:
+			n.Align = "center"
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.Next {
+		n.Children = append(n.Children, toJSONNode(child, headings))
+	}
+	return n
+}
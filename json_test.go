@@ -0,0 +1,34 @@
+package github_flavored_markdown_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestMarkdownJSON(t *testing.T) {
+	text := []byte("Intro\n\n## Title\n\nSee [docs](https://example.com \"Docs\").\n\n```go\nx\n```\n")
+	want := `{"type":"Document","children":[` +
+		`{"type":"Paragraph","children":[{"type":"Text","literal":"Intro"}]},` +
+		`{"type":"Heading","level":2,"start":7,"end":15,"children":[{"type":"Text","literal":"Title"}]},` +
+		`{"type":"Paragraph","children":[{"type":"Text","literal":"See "},{"type":"Link","destination":"https://example.com","title":"Docs","children":[{"type":"Text","literal":"docs"}]},{"type":"Text","literal":"."}]},` +
+		`{"type":"CodeBlock","literal":"x\n","info":"go"}]}`
+
+	got, err := github_flavored_markdown.MarkdownJSON(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+
+	// Documents marshal the same.
+	got, err = json.Marshal(github_flavored_markdown.Parse(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+}
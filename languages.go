@@ -0,0 +1,64 @@
+package github_flavored_markdown
+
+import "strings"
+
+// DefaultLanguageAliases maps alternative names of code block languages to
+// the name used for highlighting and for the highlight-<lang> class.
+// Names are matched case-insensitively, so keys are lowercase.
+// Options.LanguageAliases takes precedence over it.
+var DefaultLanguageAliases = map[string]string{
+	"golang": "go",
+
+	"js":         "javascript",
+	"jsx":        "javascript",
+	"mjs":        "javascript",
+	"ts":         "typescript",
+	"tsx":        "typescript",
+	"py":         "python",
+	"py3":        "python",
+	"rb":         "ruby",
+	"rs":         "rust",
+	"kt":         "kotlin",
+	"cs":         "csharp",
+	"c#":         "csharp",
+	"cpp":        "c++",
+	"cc":         "c++",
+	"hpp":        "c++",
+	"h":          "c",
+	"sh":         "bash",
+	"zsh":        "bash",
+	"ksh":        "bash",
+	"shell":      "bash",
+	"console":    "bash",
+	"yml":        "yaml",
+	"md":         "markdown",
+	"htm":        "html",
+	"xhtml":      "html",
+	"patch":      "diff",
+	"dockerfile": "docker",
+	"make":       "makefile",
+	"mk":         "makefile",
+	"ps1":        "powershell",
+	"pwsh":       "powershell",
+	"tf":         "terraform",
+	"hcl":        "terraform",
+}
+
+// canonicalLang returns the name lang is an alias of, according to
+// opts.LanguageAliases and DefaultLanguageAliases, or lang itself.
+// Languages are matched case-insensitively, and the result is lowercase.
+func canonicalLang(lang string, opts Options) string {
+	lang = normalizeLang(lang)
+	if name, ok := opts.LanguageAliases[lang]; ok {
+		return name
+	}
+	if name, ok := DefaultLanguageAliases[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// normalizeLang trims and lowercases a language name.
+func normalizeLang(lang string) string {
+	return strings.ToLower(strings.TrimSpace(lang))
+}
@@ -0,0 +1,255 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"text/template"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+const (
+	// DefaultMaxNestingDepth is the maximum nesting depth of blocks and
+	// inline elements when Options.MaxNestingDepth is zero. It is below
+	// the depth of 15 beyond which blackfriday drops content.
+	DefaultMaxNestingDepth = 12
+
+	// DefaultMaxNodes is the maximum number of nodes in a parsed document
+	// when Options.MaxNodes is zero.
+	DefaultMaxNodes = 250000
+
+	// DefaultMaxHighlightSize is the size in bytes of the largest code
+	// block that is highlighted when Options.MaxHighlightSize is zero.
+	DefaultMaxHighlightSize = 64 * 1024
+
+	// DefaultMaxHighlightLines is the number of lines of the longest code
+	// block that is highlighted when Options.MaxHighlightLines is zero.
+	DefaultMaxHighlightLines = 2000
+)
+
+// maxNestingDepth returns the maximum nesting depth for opts, or 0 for no limit.
+func (opts Options) maxNestingDepth() int {
+	switch {
+	case opts.MaxNestingDepth < 0:
+		return 0
+	case opts.MaxNestingDepth == 0:
+		return DefaultMaxNestingDepth
+	default:
+		return opts.MaxNestingDepth
+	}
+}
+
+// maxNodes returns the maximum number of nodes for opts, or 0 for no limit.
+func (opts Options) maxNodes() int {
+	switch {
+	case opts.MaxNodes < 0:
+		return 0
+	case opts.MaxNodes == 0:
+		return DefaultMaxNodes
+	default:
+		return opts.MaxNodes
+	}
+}
+
+// highlightable reports whether a code block with source src is small
+// enough to be highlighted, according to Options.MaxHighlightSize and
+// Options.MaxHighlightLines.
+func (opts Options) highlightable(src []byte) bool {
+	size, lines := opts.MaxHighlightSize, opts.MaxHighlightLines
+	if size == 0 {
+		size = DefaultMaxHighlightSize
+	}
+	if lines == 0 {
+		lines = DefaultMaxHighlightLines
+	}
+	if size > 0 && len(src) > size {
+		return false
+	}
+	return lines < 0 || bytes.Count(src, []byte("\n")) <= lines
+}
+
+var (
+	// containerMarkerRE matches a block quote or list item marker at the
+	// start of a line, or after another marker, and the whitespace after it.
+	containerMarkerRE = regexp.MustCompile(`^ {0,3}(>|[-+*](?:[ \t]|$)|[0-9]{1,9}[.)](?:[ \t]|$))[ \t]*`)
+
+	// thematicBreakRE matches a line that is a thematic break, which can
+	// look like a sequence of list item markers.
+	thematicBreakRE = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})\r?\n?$`)
+)
+
+// limitMarkers escapes the block quote and list item markers of each line
+// of text beyond the first depth, so that they are parsed as literal text
+// rather than as deeply nested blocks, which blackfriday parses
+// recursively. Lines of fenced code blocks and thematic breaks are left as is.
+func limitMarkers(text []byte, depth int) []byte {
+	var out []byte   // Allocated on the first escaped marker.
+	var fence []byte // Opening fence of the current fenced code block, if any.
+	var prev int
+	for start := 0; start < len(text); {
+		end := bytes.IndexByte(text[start:], '\n') + 1
+		if end == 0 {
+			end = len(text) - start
+		}
+		line := text[start : start+end]
+		start += end
+		if thematicBreakRE.Match(line) {
+			continue
+		}
+		var n, i int
+		esc := -1 // Offset in text of the marker to escape, if any.
+		for {
+			m := containerMarkerRE.FindSubmatchIndex(line[i:])
+			if m == nil {
+				break
+			}
+			if n == depth {
+				esc = start - end + i + m[3] - 1
+				if text[esc] == ' ' || text[esc] == '\t' || text[esc] == '\n' {
+					esc--
+				}
+				break
+			}
+			n++
+			i += m[1]
+		}
+		rest := bytes.TrimLeft(line[i:], " ")
+		switch {
+		case fence != nil:
+			if bytes.HasPrefix(rest, fence) && len(bytes.TrimLeft(rest, string(fence[:1])+" \t\r\n")) == 0 {
+				fence = nil
+			}
+			continue
+		case esc < 0 && codeFenceRE.Match(rest):
+			fence = codeFenceRE.Find(rest)
+		}
+		if esc >= 0 {
+			// Escape the punctuation of the marker.
+			out = append(append(out, text[prev:esc]...), '\\')
+			prev = esc
+		}
+	}
+	if out == nil {
+		return text
+	}
+	return append(out, text[prev:]...)
+}
+
+// limitNesting replaces the block quotes, lists and inline elements of doc
+// that are nested deeper than depth, and the nodes that come after the first
+// maxNodes nodes, with their literal text. Either limit can be 0 for none.
+// List items and the parts of tables are never replaced themselves.
+func limitNesting(doc *bf.Node, depth, maxNodes int) {
+	var replace []*bf.Node
+	var d, n int
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if node == doc || isStructural(node) {
+			return bf.GoToNext
+		}
+		if !entering {
+			if nests(node) {
+				d--
+			}
+			return bf.GoToNext
+		}
+		n++
+		if (depth > 0 && nests(node) && d >= depth) || (maxNodes > 0 && n > maxNodes) {
+			replace = append(replace, node)
+			return bf.SkipChildren
+		}
+		if nests(node) {
+			d++
+		}
+		return bf.GoToNext
+	})
+	for _, node := range replace {
+		text := newText([]byte(collectLiteral(node)))
+		if !node.IsContainer() {
+			text.Literal = node.Literal
+		}
+		if isInline(node) {
+			node.InsertBefore(text)
+		} else {
+			if node.Parent.Type == bf.Item && node.Prev != nil {
+				// Paragraphs of tight list items aren't separated.
+				text.Literal = append([]byte("\n"), text.Literal...)
+			}
+			p := bf.NewNode(bf.Paragraph)
+			p.AppendChild(text)
+			node.InsertBefore(p)
+		}
+		node.Unlink()
+	}
+}
+
+// nests reports whether node counts towards the nesting depth.
+func nests(node *bf.Node) bool {
+	switch node.Type {
+	case bf.BlockQuote, bf.List, bf.Emph, bf.Strong, bf.Del, bf.Link, bf.Image:
+		return true
+	}
+	return false
+}
+
+// isStructural reports whether node is a list item or part of a table,
+// which only the nodes around it can contain.
+func isStructural(node *bf.Node) bool {
+	switch node.Type {
+	case bf.Item, bf.TableHead, bf.TableBody, bf.TableRow, bf.TableCell:
+		return true
+	}
+	return false
+}
+
+// isInline reports whether node is an inline node.
+func isInline(node *bf.Node) bool {
+	switch node.Type {
+	case bf.Emph, bf.Strong, bf.Del, bf.Link, bf.Image, bf.Text, bf.Code, bf.HTMLSpan, bf.Hardbreak, bf.Softbreak:
+		return true
+	}
+	return false
+}
+
+// OutputTooLargeError is returned when the rendered output is larger than
+// Options.MaxOutputSize.
+type OutputTooLargeError struct {
+	Limit int64 // Maximum output size in bytes.
+}
+
+func (e *OutputTooLargeError) Error() string {
+	return fmt.Sprintf("github_flavored_markdown: output larger than %d bytes", e.Limit)
+}
+
+// limitedWriter writes to w until n bytes have been written. Writes beyond
+// that are dropped, and set err to an *OutputTooLargeError.
+type limitedWriter struct {
+	w     io.Writer
+	n     int64 // Bytes left.
+	limit int64
+	err   error
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if int64(len(p)) > l.n {
+		l.err = &OutputTooLargeError{Limit: l.limit}
+		return 0, l.err
+	}
+	l.n -= int64(len(p))
+	return l.w.Write(p)
+}
+
+// plainText returns text as escaped plain text, the output of the functions
+// that don't return errors when the output is larger than
+// Options.MaxOutputSize.
+func plainText(text []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<pre>")
+	template.HTMLEscape(&buf, text)
+	buf.WriteString("</pre>\n")
+	return buf.Bytes()
+}
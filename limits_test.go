@@ -0,0 +1,96 @@
+package github_flavored_markdown_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestWithNestingLimits(t *testing.T) {
+	tests := []struct {
+		text  string
+		depth int
+		nodes int
+		want  string
+	}{
+		{
+			text:  "> > > > a\n",
+			depth: 2,
+			want:  "<blockquote>\n<blockquote>\n<p>&gt; &gt; a</p>\n</blockquote>\n</blockquote>\n",
+		},
+		{
+			text:  "- a\n    - b\n        - c\n",
+			depth: 2,
+			want:  "<ul>\n<li>a\n\n<ul>\n<li>b\nc</li>\n</ul></li>\n</ul>\n",
+		},
+		{
+			text:  "> a *b **c***\n",
+			depth: 1,
+			want:  "<blockquote>\n<p>a *b c*</p>\n</blockquote>\n",
+		},
+		{
+			text:  "a\n\nb *c* d\n\n```\ncode\n```\n",
+			nodes: 4,
+			want:  "<p>a</p>\n\n<p>b c d</p>\n\n<p>code\n</p>\n",
+		},
+		{
+			// Fenced code is kept as is.
+			text:  "```\n> > > > a\n```\n",
+			depth: 2,
+			want:  "<pre><code>&gt; &gt; &gt; &gt; a\n</code></pre>",
+		},
+		{
+			text:  "> > > > a\n",
+			depth: -1,
+			want:  "<blockquote>\n<blockquote>\n<blockquote>\n<blockquote>\n<p>a</p>\n</blockquote>\n</blockquote>\n</blockquote>\n</blockquote>\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithNestingLimits(test.depth, test.nodes))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+
+	// Deeply nested block quotes are limited by default.
+	text := strings.Repeat(">", 100000) + " a\n"
+	want := strings.Repeat(">", 100000-github_flavored_markdown.DefaultMaxNestingDepth) + " a"
+	got := string(github_flavored_markdown.Markdown([]byte(text)))
+	if n := strings.Count(got, "<blockquote>"); n != github_flavored_markdown.DefaultMaxNestingDepth {
+		t.Errorf("got %d nested block quotes, want %d", n, github_flavored_markdown.DefaultMaxNestingDepth)
+	}
+	if !strings.Contains(got, strings.Replace(want, ">", "&gt;", -1)) {
+		t.Error("the deeper block quotes are not kept as text")
+	}
+}
+
+func TestWithMaxOutputSize(t *testing.T) {
+	// Reference links expand to much more output than input.
+	text := "[a][r] [a][r] [a][r] [a][r]\n\n[r]: https://example.com/" + strings.Repeat("x", 100) + "\n"
+
+	got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithMaxOutputSize(200)))
+	if want := "<pre>[a][r] [a][r] [a][r] [a][r]\n\n[r]: https://example.com/" + strings.Repeat("x", 100) + "\n</pre>\n"; got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	_, err := github_flavored_markdown.MarkdownContext(context.Background(), []byte(text), github_flavored_markdown.WithMaxOutputSize(200))
+	if e, ok := err.(*github_flavored_markdown.OutputTooLargeError); !ok || e.Limit != 200 {
+		t.Errorf("got error %v, want *OutputTooLargeError with limit 200", err)
+	}
+	if err := github_flavored_markdown.MarkdownTo(io.Discard, []byte(text), github_flavored_markdown.WithMaxOutputSize(200)); err == nil {
+		t.Error("MarkdownTo: got nil error, want *OutputTooLargeError")
+	}
+
+	// Output within the limit is not affected.
+	want := github_flavored_markdown.Markdown([]byte(text))
+	out, err := github_flavored_markdown.MarkdownContext(context.Background(), []byte(text), github_flavored_markdown.WithMaxOutputSize(2000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(want) {
+		t.Errorf("\ngot %q\nwant %q", out, want)
+	}
+}
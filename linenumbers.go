@@ -0,0 +1,154 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// formatLines splits the highlighted or escaped code into lines,
+// prefixing each line with a <span class="ln"> containing its line number
+// if number is set, and wrapping the lines in highlight with a
+// <span class="hl">. Elements that are open at the end of a line, such as
+// a multi-line comment span, are closed before the line break and reopened
+// on the next line, so that each line is well-formed on its own.
+func formatLines(code []byte, number bool, highlight lineRanges) []byte {
+	var buf bytes.Buffer
+	var open []openTag // Currently open elements.
+	line := 0
+	inLine := false
+	startLine := func() {
+		if inLine {
+			return
+		}
+		line++
+		if highlight.contains(line) {
+			buf.WriteString(`<span class="hl">`)
+		}
+		if number {
+			fmt.Fprintf(&buf, `<span class="ln">%d</span>`, line)
+		}
+		for _, tag := range open {
+			buf.Write(tag.raw)
+		}
+		inLine = true
+	}
+	closeLine := func() {
+		closeTags(&buf, open)
+		if highlight.contains(line) {
+			buf.WriteString(`</span>`)
+		}
+	}
+	endLine := func() {
+		startLine()
+		closeLine()
+		buf.WriteByte('\n')
+		inLine = false
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(code))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if inLine {
+				closeLine()
+			}
+			return buf.Bytes()
+		case html.StartTagToken:
+			startLine()
+			raw := append([]byte(nil), z.Raw()...)
+			name, _ := z.TagName()
+			open = append(open, openTag{raw: raw, name: string(name)})
+			buf.Write(raw)
+		case html.EndTagToken:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+			// At the start of a line, the element was already closed by endLine.
+			if inLine {
+				buf.Write(z.Raw())
+			}
+		case html.TextToken:
+			text := z.Raw()
+			for {
+				i := bytes.IndexByte(text, '\n')
+				if i < 0 {
+					if len(text) > 0 {
+						startLine()
+						buf.Write(text)
+					}
+					break
+				}
+				if i > 0 {
+					startLine()
+					buf.Write(text[:i])
+				}
+				endLine()
+				text = text[i+1:]
+			}
+		default:
+			startLine()
+			buf.Write(z.Raw())
+		}
+	}
+}
+
+type openTag struct {
+	raw  []byte // Raw start tag.
+	name string
+}
+
+// closeTags writes end tags for the open elements, innermost first.
+func closeTags(buf *bytes.Buffer, open []openTag) {
+	for i := len(open) - 1; i >= 0; i-- {
+		fmt.Fprintf(buf, "</%s>", open[i].name)
+	}
+}
+
+// lineRanges is a set of line numbers, such as the {3-5,8} in a code fence
+// info string.
+type lineRanges [][2]int
+
+// parseLineRanges parses the comma-separated line numbers and inclusive
+// ranges between braces in info. Malformed entries are ignored.
+func parseLineRanges(info []byte) lineRanges {
+	start := bytes.IndexByte(info, '{')
+	if start < 0 {
+		return nil
+	}
+	end := bytes.IndexByte(info[start:], '}')
+	if end < 0 {
+		return nil
+	}
+	var ranges lineRanges
+	for _, field := range strings.Split(string(info[start+1:start+end]), ",") {
+		field = strings.TrimSpace(field)
+		from, to := field, field
+		if i := strings.IndexByte(field, '-'); i >= 0 {
+			from, to = strings.TrimSpace(field[:i]), strings.TrimSpace(field[i+1:])
+		}
+		a, err := strconv.Atoi(from)
+		if err != nil || a < 1 {
+			continue
+		}
+		b, err := strconv.Atoi(to)
+		if err != nil || b < a {
+			continue
+		}
+		ranges = append(ranges, [2]int{a, b})
+	}
+	return ranges
+}
+
+// contains reports whether line is in one of the ranges.
+func (lr lineRanges) contains(line int) bool {
+	for _, r := range lr {
+		if r[0] <= line && line <= r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package github_flavored_markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNumberLines(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{
+			code: "a &lt; b\nc\n",
+			want: `<span class="ln">1</span>a &lt; b` + "\n" + `<span class="ln">2</span>c` + "\n",
+		},
+		{
+			// Spans across lines are closed and reopened.
+			code: `<span class="c">/* a` + "\n" + `b */</span> x` + "\n",
+			want: `<span class="ln">1</span><span class="c">/* a</span>` + "\n" + `<span class="ln">2</span><span class="c">b */</span> x` + "\n",
+		},
+		{
+			// A span ending right after a line break.
+			code: `<span class="gi">+a` + "\n" + `</span>`,
+			want: `<span class="ln">1</span><span class="gi">+a</span>` + "\n",
+		},
+		{
+			// No trailing newline, and an empty line.
+			code: "a\n\n<b>b</b>",
+			want: `<span class="ln">1</span>a` + "\n" + `<span class="ln">2</span>` + "\n" + `<span class="ln">3</span><b>b</b>`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(formatLines([]byte(test.code), true, nil)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestFormatLinesHighlight(t *testing.T) {
+	code := `<span class="c">/* a` + "\n" + `b */</span>` + "\n" + "c\n"
+	want := `<span class="hl"><span class="c">/* a</span></span>` + "\n" +
+		`<span class="hl"><span class="c">b */</span></span>` + "\n" +
+		"c\n"
+
+	if got := string(formatLines([]byte(code), false, lineRanges{{1, 2}})); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestParseLineRanges(t *testing.T) {
+	tests := []struct {
+		info string
+		want lineRanges
+	}{
+		{info: "go", want: nil},
+		{info: "go {3-5,8}", want: lineRanges{{3, 5}, {8, 8}}},
+		{info: "go{ 1 , 2 - 3 }", want: lineRanges{{1, 1}, {2, 3}}},
+		{info: "go {5-3,x,0,-1,4}", want: lineRanges{{4, 4}}},
+		{info: "go {1", want: nil},
+	}
+
+	for _, test := range tests {
+		if got := parseLineRanges([]byte(test.info)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %v, want %v", test.info, got, test.want)
+		}
+	}
+}
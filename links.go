@@ -0,0 +1,200 @@
+package github_flavored_markdown
+
+import (
+	"html"
+	"io"
+	"net/url"
+	"strings"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// DefaultTrackingParams are the query parameters removed by
+// Options.StripTrackingParams when Options.TrackingParams is nil.
+var DefaultTrackingParams = []string{"utm_*", "fbclid", "gclid"}
+
+// rewriteURLs walks the parsed document and rewrites link and image
+// destinations according to opts.
+func rewriteURLs(doc *bf.Node, opts Options) {
+	rewrite := urlRewriter(opts)
+	if rewrite == nil {
+		return
+	}
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering || (node.Type != bf.Link && node.Type != bf.Image) {
+			return bf.GoToNext
+		}
+		dest := rewrite(string(node.LinkData.Destination), node.Type == bf.Image)
+		node.LinkData.Destination = []byte(dest)
+		return bf.GoToNext
+	})
+}
+
+// urlRewriter returns a function that rewrites link and image destinations
+// according to opts, or nil if opts don't rewrite them.
+func urlRewriter(opts Options) func(dest string, isImage bool) string {
+	var base *url.URL
+	if opts.BaseURL != "" {
+		base, _ = url.Parse(opts.BaseURL)
+	}
+	if !opts.StripTrackingParams && base == nil && opts.ImageProxy == nil && opts.LinkRewriter == nil {
+		return nil
+	}
+	params := opts.TrackingParams
+	if params == nil {
+		params = DefaultTrackingParams
+	}
+	return func(dest string, isImage bool) string {
+		if opts.StripTrackingParams {
+			dest = stripTrackingParams(dest, params)
+		}
+		if base != nil {
+			dest = resolveURL(base, dest)
+		}
+		if opts.ImageProxy != nil && isImage {
+			dest = opts.ImageProxy.rewrite(dest)
+		}
+		if opts.LinkRewriter != nil {
+			dest = opts.LinkRewriter(dest, isImage)
+		}
+		return dest
+	}
+}
+
+// isExternal reports whether dest links to a host other than
+// r.opts.InternalHosts.
+func (r *renderer) isExternal(dest []byte) bool {
+	u, err := url.Parse(string(dest))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := u.Hostname()
+	for _, h := range r.opts.InternalHosts {
+		if strings.EqualFold(h, host) {
+			return false
+		}
+	}
+	return true
+}
+
+// link renders a link with the rel tokens of r.opts, opening it in a new
+// tab if it's external and r.opts.ExternalLinksNewTab is set.
+func (r *renderer) link(w io.Writer, node *bf.Node, entering bool) bf.WalkStatus {
+	if !entering {
+		io.WriteString(w, "</a>")
+		return bf.GoToNext
+	}
+	external := r.isExternal(node.LinkData.Destination)
+	io.WriteString(w, `<a href="`)
+	attrEscape(w, node.LinkData.Destination)
+	io.WriteString(w, `"`)
+	if len(node.LinkData.Title) > 0 {
+		io.WriteString(w, ` title="`)
+		attrEscape(w, node.LinkData.Title)
+		io.WriteString(w, `"`)
+	}
+	rel := r.rel(external)
+	if external && r.opts.ExternalLinksNewTab {
+		io.WriteString(w, ` target="_blank"`)
+		rel = appendRel([]string{"noopener", "noreferrer"}, rel...)
+	}
+	io.WriteString(w, relAttr(rel)+">")
+	return bf.GoToNext
+}
+
+// rel returns the rel tokens of external or internal links.
+func (r *renderer) rel(external bool) []string {
+	rel := r.opts.InternalLinkRel
+	if external {
+		rel = r.opts.LinkRel
+	}
+	if rel == nil {
+		return []string{"nofollow"}
+	}
+	return rel
+}
+
+// appendRel appends the tokens to rel that it doesn't contain yet.
+func appendRel(rel []string, tokens ...string) []string {
+outer:
+	for _, t := range tokens {
+		for _, r := range rel {
+			if r == t {
+				continue outer
+			}
+		}
+		rel = append(rel, t)
+	}
+	return rel
+}
+
+// relAttr returns the rel attribute for tokens, or "" if there are none.
+func relAttr(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return ` rel="` + html.EscapeString(strings.Join(tokens, " ")) + `"`
+}
+
+// resolveURL resolves the relative URL rawurl against base. Absolute URLs,
+// links to fragments within the document, and invalid URLs are returned
+// unchanged.
+func resolveURL(base *url.URL, rawurl string) string {
+	if rawurl == "" || strings.HasPrefix(rawurl, "#") {
+		return rawurl
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil || u.IsAbs() {
+		return rawurl
+	}
+	return base.ResolveReference(u).String()
+}
+
+// stripTrackingParams removes the query parameters matching params from rawurl.
+// The order of the remaining parameters and the rest of the URL are preserved.
+func stripTrackingParams(rawurl string, params []string) string {
+	if _, err := url.Parse(rawurl); err != nil {
+		return rawurl
+	}
+	var fragment string
+	if i := strings.IndexByte(rawurl, '#'); i >= 0 {
+		rawurl, fragment = rawurl[:i], rawurl[i:]
+	}
+	i := strings.IndexByte(rawurl, '?')
+	if i < 0 {
+		return rawurl + fragment
+	}
+	base, query := rawurl[:i], rawurl[i+1:]
+
+	var kept []string
+	for _, pair := range strings.Split(query, "&") {
+		key := pair
+		if j := strings.IndexByte(key, '='); j >= 0 {
+			key = key[:j]
+		}
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if !matchParam(key, params) {
+			kept = append(kept, pair)
+		}
+	}
+	if len(kept) == 0 {
+		return base + fragment
+	}
+	return base + "?" + strings.Join(kept, "&") + fragment
+}
+
+// matchParam reports whether key matches any of params.
+func matchParam(key string, params []string) bool {
+	for _, p := range params {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(key, p[:len(p)-1]) {
+				return true
+			}
+		} else if key == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package github_flavored_markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestStripTrackingParams(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			// Tracking parameters are stripped from the destination, but not the link text.
+			text: "https://example.com/page?utm_source=news&id=1&fbclid=abc",
+			want: `<p><a href="https://example.com/page?id=1" rel="nofollow">https://example.com/page?utm_source=news&amp;id=1&amp;fbclid=abc</a></p>` + "\n",
+		},
+		{
+			// Images too.
+			text: "![logo](https://example.com/logo.png?utm_medium=email)",
+			want: `<p><img src="https://example.com/logo.png" alt="logo"/></p>` + "\n",
+		},
+		{
+			// Clean URL, should be unchanged.
+			text: "[clean](https://example.com/page?id=1#top)",
+			want: `<p><a href="https://example.com/page?id=1#top" rel="nofollow">clean</a></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		opts := github_flavored_markdown.Options{StripTrackingParams: true}
+		if got := string(github_flavored_markdown.MarkdownOptions([]byte(test.text), opts)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestStripTrackingParamsCustom(t *testing.T) {
+	text := "[x](https://example.com/?ref=feed&utm_source=news)"
+	want := `<p><a href="https://example.com/?utm_source=news" rel="nofollow">x</a></p>` + "\n"
+
+	opts := github_flavored_markdown.Options{StripTrackingParams: true, TrackingParams: []string{"ref"}}
+	if got := string(github_flavored_markdown.MarkdownOptions([]byte(text), opts)); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "[Intro](./docs/intro.md) ![logo](img/logo.png)",
+			want: `<p><a href="https://github.com/o/r/blob/main/docs/intro.md" rel="nofollow">Intro</a> <img src="https://github.com/o/r/blob/main/img/logo.png" alt="logo"/></p>` + "\n",
+		},
+		{
+			text: "[Up](../other/README.md) [Root](/LICENSE)",
+			want: `<p><a href="https://github.com/o/r/blob/other/README.md" rel="nofollow">Up</a> <a href="https://github.com/LICENSE" rel="nofollow">Root</a></p>` + "\n",
+		},
+		{
+			// Absolute URLs and fragments are unchanged.
+			text: "[a](https://example.com/x) [b](#usage) [c](mailto:me@example.com)",
+			want: `<p><a href="https://example.com/x" rel="nofollow">a</a> <a href="#usage" rel="nofollow">b</a> <a href="mailto:me@example.com" rel="nofollow">c</a></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithBaseURL("https://github.com/o/r/blob/main/")))
+		if got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestWithLinkRewriter(t *testing.T) {
+	rewrite := func(dest string, isImage bool) string {
+		if isImage {
+			return "https://cdn.example.com/" + dest
+		}
+		return strings.TrimSuffix(dest, ".md") + ".html"
+	}
+	text := "[Intro](docs/intro.md) ![logo](logo.png) www.example.com/a.md"
+	want := `<p><a href="docs/intro.html" rel="nofollow">Intro</a> <img src="https://cdn.example.com/logo.png" alt="logo"/> <a href="http://www.example.com/a.html" rel="nofollow">www.example.com/a.md</a></p>` + "\n"
+
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithLinkRewriter(rewrite))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestWithExternalLinksNewTab(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: `[a](https://example.net/a "A") www.example.net`,
+			want: `<p><a href="https://example.net/a" title="A" target="_blank" rel="noopener noreferrer nofollow">a</a> <a href="http://www.example.net" target="_blank" rel="noopener noreferrer nofollow">www.example.net</a></p>` + "\n",
+		},
+		{
+			// Internal and relative links are not changed.
+			text: "[b](https://EXAMPLE.com/b) [c](c.md) [d](#d)",
+			want: `<p><a href="https://EXAMPLE.com/b" rel="nofollow">b</a> <a href="c.md" rel="nofollow">c</a> <a href="#d" rel="nofollow">d</a></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithExternalLinksNewTab("example.com"))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestWithLinkRel(t *testing.T) {
+	tests := []struct {
+		text string
+		opts []github_flavored_markdown.Option
+		want string
+	}{
+		{
+			text: "# A\n\n[b](https://example.net/b) [c](c.md)",
+			opts: []github_flavored_markdown.Option{
+				github_flavored_markdown.WithLinkRel("ugc", "nofollow"),
+				github_flavored_markdown.WithInternalLinkRel(),
+			},
+			want: `<h1><a name="a" class="anchor" href="#a" aria-hidden="true"><span class="octicon octicon-link"></span></a>A</h1>` + "\n\n" +
+				`<p><a href="https://example.net/b" rel="ugc nofollow noreferrer">b</a> <a href="c.md">c</a></p>` + "\n",
+		},
+		{
+			// Internal links keep nofollow by default.
+			text: "[b](https://example.net/b) [c](https://example.com/c)",
+			opts: []github_flavored_markdown.Option{
+				github_flavored_markdown.WithLinkRel("sponsored"),
+				github_flavored_markdown.WithExternalLinksNewTab("example.com"),
+			},
+			want: `<p><a href="https://example.net/b" target="_blank" rel="noopener noreferrer sponsored">b</a> <a href="https://example.com/c" rel="nofollow noreferrer">c</a></p>` + "\n",
+		},
+		{
+			// Links in raw HTML can't open other sites with access to the page.
+			text: `<a href="https://example.net/b" target="_blank">b</a> <a href="c.md" target="_blank" rel="ugc">c</a>`,
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithLinkRel("ugc")},
+			want: `<p><a href="https://example.net/b" target="_blank" rel="noreferrer noopener">b</a> <a href="c.md" target="_blank" rel="ugc noopener">c</a></p>` + "\n",
+		},
+		{
+			text: `<a href="https://example.net/b" target="_blank">b</a>`,
+			opts: []github_flavored_markdown.Option{
+				github_flavored_markdown.WithInternalLinkRel("ugc"),
+				github_flavored_markdown.WithURLSchemes("irc"),
+			},
+			want: `<p><a href="https://example.net/b" target="_blank" rel="noreferrer noopener">b</a></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), test.opts...)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// TableWarning describes a table row whose cell count doesn't match
+// the table's header.
+type TableWarning struct {
+	Line    int // Line number of the row in the source, starting at 1.
+	Columns int // Number of columns in the header row.
+	Cells   int // Number of cells in the row.
+}
+
+func (w TableWarning) String() string {
+	return fmt.Sprintf("line %d: row has %d cells, header has %d columns", w.Line, w.Cells, w.Columns)
+}
+
+// LintTables reports the rows of GitHub Flavored Markdown tables in text
+// whose cell count differs from the number of header columns,
+// including a mismatched delimiter row.
+//
+// blackfriday pads and truncates rows to the header's column count while
+// parsing, so the cells are counted in the source lines rather than in
+// the parsed bf.TableRow nodes.
+func LintTables(text []byte) []TableWarning {
+	var warnings []TableWarning
+	lines := bytes.Split(text, []byte("\n"))
+	var fence []byte
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if f := fenceMarker(line); f != nil {
+			switch {
+			case fence == nil:
+				fence = f
+			case bytes.HasPrefix(f, fence):
+				fence = nil
+			}
+			continue
+		}
+		if fence != nil || i+1 >= len(lines) || !bytes.ContainsRune(line, '|') || !isDelimiterRow(lines[i+1]) {
+			continue
+		}
+
+		columns := countCells(line)
+		if n := countCells(lines[i+1]); n != columns {
+			warnings = append(warnings, TableWarning{Line: i + 2, Columns: columns, Cells: n})
+		}
+		i += 2
+		for ; i < len(lines) && len(bytes.TrimSpace(lines[i])) > 0; i++ {
+			if n := countCells(lines[i]); n != columns {
+				warnings = append(warnings, TableWarning{Line: i + 1, Columns: columns, Cells: n})
+			}
+		}
+	}
+	return warnings
+}
+
+// fenceMarker returns the run of backticks or tildes that opens or closes
+// a fenced code block on line, or nil if line isn't a fence.
+func fenceMarker(line []byte) []byte {
+	trimmed := bytes.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return nil
+	}
+	c := trimmed[0]
+	if c != '`' && c != '~' {
+		return nil
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == c {
+		n++
+	}
+	if n < 3 {
+		return nil
+	}
+	return trimmed[:n]
+}
+
+// isDelimiterRow reports whether line is a table delimiter row, such as "|---|:-:|".
+func isDelimiterRow(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	if !bytes.ContainsRune(line, '-') {
+		return false
+	}
+	for _, c := range line {
+		switch c {
+		case '|', ':', '-', ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// countCells returns the number of cells in a table row, ignoring
+// optional leading and trailing pipes and escaped pipes.
+func countCells(line []byte) int {
+	line = bytes.TrimSpace(line)
+	if len(line) > 0 && line[0] == '|' {
+		line = line[1:]
+	}
+	if n := len(line); n > 0 && line[n-1] == '|' && (n < 2 || line[n-2] != '\\') {
+		line = line[:n-1]
+	}
+	cells := 1
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			cells++
+		}
+	}
+	return cells
+}
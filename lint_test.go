@@ -0,0 +1,47 @@
+package github_flavored_markdown_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestLintTables(t *testing.T) {
+	tests := []struct {
+		text string
+		want []github_flavored_markdown.TableWarning
+	}{
+		{
+			// Well-formed table, including an escaped pipe.
+			text: "| a | b |\n|---|:-:|\n| 1 | 2 \\| 3 |\n",
+			want: nil,
+		},
+		{
+			// Ragged rows.
+			text: "Intro.\n\n| a | b |\n| --- | --- |\n| 1 | 2 | 3 |\n| 4 |\n",
+			want: []github_flavored_markdown.TableWarning{
+				{Line: 5, Columns: 2, Cells: 3},
+				{Line: 6, Columns: 2, Cells: 1},
+			},
+		},
+		{
+			// Mismatched delimiter row.
+			text: "a | b | c\n--|--\n",
+			want: []github_flavored_markdown.TableWarning{
+				{Line: 2, Columns: 3, Cells: 2},
+			},
+		},
+		{
+			// Tables inside fenced code blocks are not checked.
+			text: "```\n| a | b |\n|---|---|\n| 1 |\n```\n",
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		if got := github_flavored_markdown.LintTables([]byte(test.text)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("\ngot %v\nwant %v", got, test.want)
+		}
+	}
+}
@@ -4,20 +4,23 @@ with fenced code block highlighting, clickable heading anchor links.
 
 The functionality should be equivalent to the GitHub Markdown API endpoint specified at
 https://developer.github.com/v3/markdown/#render-a-markdown-document-in-raw-mode, except
-the rendering is performed locally.
+the rendering is performed locally. Compare measures how closely the output
+matches that of the endpoint.
 
 See examples for how to generate a complete HTML page, including CSS styles.
 */
 package github_flavored_markdown
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/shurcooL/highlight_diff"
 	"github.com/shurcooL/highlight_go"
 	"github.com/shurcooL/octiconssvg"
-	"github.com/shurcooL/sanitized_anchor_name"
 	"github.com/sourcegraph/annotate"
 	"github.com/sourcegraph/syntaxhighlight"
 	"golang.org/x/net/html"
@@ -26,24 +29,460 @@ import (
 	"io"
 	"regexp"
 	"sort"
+	"strings"
 	"text/template"
+	"time"
+	"unicode/utf8"
 )
 
 // Markdown renders GitHub Flavored Markdown text.
-func Markdown(text []byte) []byte {
-	const htmlFlags = 0
+// Rendering can be customized with options, such as WithFootnotes().
+func Markdown(text []byte, opts ...Option) []byte {
+	return MarkdownOptions(text, newOptions(opts))
+}
 
-	params := bf.HTMLRendererParameters{
-		Flags: htmlFlags,
+// MarkdownOptions renders GitHub Flavored Markdown text using the given options.
+func MarkdownOptions(text []byte, opts Options) []byte {
+	out, err := markdown(context.Background(), text, opts)
+	if _, ok := err.(*OutputTooLargeError); ok {
+		return plainText(text)
 	}
+	return out
+}
 
-	renderer := &renderer{
-		HTMLRenderer: bf.NewHTMLRenderer(params),
+// markdown renders and sanitizes text. If rendering is stopped, it returns
+// the output rendered so far and the reason, except for an
+// *OutputTooLargeError, which it returns without output.
+func markdown(ctx context.Context, text []byte, opts Options) ([]byte, error) {
+	if opts.Cache != nil {
+		return cachedMarkdown(ctx, text, opts)
+	}
+	if len(text) >= streamMinSize && opts.sanitizer() != nil {
+		var buf bytes.Buffer
+		err := renderTo(ctx, &buf, text, opts)
+		if _, ok := err.(*OutputTooLargeError); ok {
+			return nil, err
+		}
+		return buf.Bytes(), err
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	out, err := render(ctx, buf, text, opts)
+	if _, ok := err.(*OutputTooLargeError); ok {
+		return nil, err
+	}
+	return sanitize(out, opts), err
+}
+
+// cachedMarkdown is like markdown, but returns the output from opts.Cache if
+// it has it, and adds the output to it otherwise.
+func cachedMarkdown(ctx context.Context, text []byte, opts Options) ([]byte, error) {
+	key, ok := cacheKey(text, opts)
+	c := opts.Cache
+	opts.Cache = nil
+	if !ok {
+		return markdown(ctx, text, opts)
+	}
+	if out, ok := c.Get(key); ok {
+		// Callers may modify the output.
+		return append([]byte(nil), out...), nil
+	}
+	out, err := markdown(ctx, text, opts)
+	if err == nil {
+		c.Add(key, append([]byte(nil), out...))
+	}
+	return out, err
+}
+
+// sanitize returns unsanitized HTML sanitized with the policy of opts.
+// The result never shares memory with unsanitized, which can be in
+// a pooled buffer.
+func sanitize(unsanitized []byte, opts Options) []byte {
+	p := opts.sanitizer()
+	if p == nil {
+		return append([]byte(nil), unsanitized...)
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := p.SanitizeReaderToWriter(bytes.NewReader(unsanitized), buf); err != nil {
+		// Like SanitizeBytes.
+		return nil
+	}
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+// MarkdownTo renders GitHub Flavored Markdown text like Markdown,
+// writing the output to w instead of returning it. The output is written
+// as it is rendered, in chunks of whole top-level blocks, unless options
+// that rewrite the complete output, such as WithDirAuto, are used. If
+// rendering times out, it writes the output rendered so far and returns
+// ErrTimeout. If the output is larger than Options.MaxOutputSize, it returns
+// an *OutputTooLargeError, possibly after writing part of the output.
+func MarkdownTo(w io.Writer, text []byte, opts ...Option) error {
+	return markdownTo(w, text, newOptions(opts))
+}
+
+// markdownTo implements MarkdownTo with the given options.
+func markdownTo(w io.Writer, text []byte, opts Options) error {
+	if err := checkInputSize(len(text), opts); err != nil {
+		return err
+	}
+	if opts.Cache != nil {
+		out, err := cachedMarkdown(context.Background(), text, opts)
+		if _, werr := w.Write(out); werr != nil {
+			return werr
+		}
+		return err
+	}
+	return renderTo(context.Background(), w, text, opts)
+}
+
+// MarkdownContext renders GitHub Flavored Markdown text like Markdown.
+// If ctx is done before rendering completes, it stops and returns
+// the output rendered so far along with ctx.Err(). Rendering is stopped
+// between top-level blocks and while highlighting diffs. If rendering
+// times out, it returns the output rendered so far along with ErrTimeout.
+func MarkdownContext(ctx context.Context, text []byte, opts ...Option) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+	if err := checkInputSize(len(text), o); err != nil {
+		return nil, err
+	}
+	return markdown(ctx, text, o)
+}
+
+// MarkdownNode renders GitHub Flavored Markdown text like Markdown, and
+// returns the output parsed as HTML, for further processing. The returned
+// node is a document node whose children are the top-level elements of the
+// output.
+func MarkdownNode(text []byte, opts ...Option) *html.Node {
+	out := Markdown(text, opts...)
+	body := &html.Node{Type: html.ElementNode, Data: atom.Body.String(), DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(bytes.NewReader(out), body)
+	if err != nil {
+		// Reading from a bytes.Reader can't fail.
+		panic(err)
+	}
+	doc := &html.Node{Type: html.DocumentNode}
+	for _, n := range nodes {
+		doc.AppendChild(n)
+	}
+	return doc
+}
+
+// MarkdownReader renders GitHub Flavored Markdown read from r.
+// If r has more than Options.MaxInputSize bytes, it returns an
+// *InputTooLargeError without rendering. If rendering times out, it returns
+// the output rendered so far along with ErrTimeout.
+func MarkdownReader(r io.Reader, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	if max := o.maxInputSize(); max > 0 {
+		r = io.LimitReader(r, max+1)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if err := checkInputSize(buf.Len(), o); err != nil {
+		return nil, err
+	}
+	return markdown(context.Background(), buf.Bytes(), o)
+}
+
+// DefaultMaxInputSize is the maximum input size when Options.MaxInputSize
+// is zero. It is the size of the largest files GitHub renders.
+const DefaultMaxInputSize = 400 * 1024
+
+// InputTooLargeError is returned when the input is larger than
+// Options.MaxInputSize.
+type InputTooLargeError struct {
+	Limit int64 // Maximum input size in bytes.
+}
+
+func (e *InputTooLargeError) Error() string {
+	return fmt.Sprintf("github_flavored_markdown: input larger than %d bytes", e.Limit)
+}
+
+// ErrTimeout is returned along with the output rendered so far when
+// rendering takes longer than Options.Timeout.
+var ErrTimeout = errors.New("github_flavored_markdown: rendering timed out")
+
+// checkInputSize returns an *InputTooLargeError if n bytes of input are
+// more than opts allow.
+func checkInputSize(n int, opts Options) error {
+	if max := opts.maxInputSize(); max > 0 && int64(n) > max {
+		return &InputTooLargeError{Limit: max}
+	}
+	return nil
+}
+
+// truncateInput returns text cut to at most max bytes, after the last
+// newline within the limit, or at a rune boundary if there is none.
+// A max of 0 means no limit.
+func truncateInput(text []byte, max int64) []byte {
+	if max <= 0 || int64(len(text)) <= max {
+		return text
+	}
+	text = text[:max]
+	if i := bytes.LastIndexByte(text, '\n'); i >= 0 {
+		return text[:i+1]
+	}
+	// Drop the bytes of a rune that was cut.
+	for i := len(text) - 1; i >= 0 && i >= len(text)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(text[i]) {
+			if !utf8.FullRune(text[i:]) {
+				text = text[:i]
+			}
+			break
+		}
 	}
+	return text
+}
+
+// MarkdownTrusted renders GitHub Flavored Markdown text like Markdown,
+// but without sanitizing the output, which is considerably faster.
+// It is equivalent to Markdown(text, Unsafe()).
+//
+// Raw HTML in text, including <script> elements, event handler attributes
+// and javascript: URLs, is passed through unchanged. MarkdownTrusted must
+// never be used with untrusted input.
+func MarkdownTrusted(text []byte) []byte {
+	return MarkdownOptions(text, Options{SkipSanitization: true})
+}
+
+// render renders text to unsanitized HTML, using buf for the output. If ctx
+// is done or opts.Timeout elapses before rendering completes, it returns the
+// output rendered so far and ctx.Err() or ErrTimeout.
+func render(ctx context.Context, buf *bytes.Buffer, text []byte, opts Options) ([]byte, error) {
+	if opts.Engine == Goldmark && goldmarkRender != nil {
+		return goldmarkRender(ctx, buf, text, opts)
+	}
+	renderer := newRenderer(opts)
+	defer renderer.setContext(ctx)()
+	doc, notes := renderer.parse(text)
+	return renderer.render(buf, doc, notes)
+}
+
+// goldmarkRender renders text like render, with the Goldmark engine. It's
+// nil unless the package is built with the goldmark build tag.
+var goldmarkRender func(ctx context.Context, buf *bytes.Buffer, text []byte, opts Options) ([]byte, error)
+
+// streamMinSize is the input size from which markdown streams the rendered
+// output through the sanitizer. Buffering it is faster for smaller input.
+const streamMinSize = 64 * 1024
+
+// streamBufferSize is the size of the buffer renderTo writes the output of
+// each top-level block through.
+const streamBufferSize = 32 * 1024
+
+// renderTo renders text and writes it to w, sanitized with the policy of
+// opts. Unless the output is rewritten after rendering, it is sanitized as
+// it is rendered and written to w after each top-level block, so that it is
+// never held in memory in full. It returns
+// the error from writing to w, if any, or else ctx.Err() or ErrTimeout if
+// rendering was stopped.
+func renderTo(ctx context.Context, w io.Writer, text []byte, opts Options) error {
+	p := opts.sanitizer()
+	if opts.Engine == Goldmark && goldmarkRender != nil {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		out, err := goldmarkRender(ctx, buf, text, opts)
+		return writeRendered(w, out, err, p)
+	}
+
+	renderer := newRenderer(opts)
+	defer renderer.setContext(ctx)()
+	doc, notes := renderer.parse(text)
+
+	if renderer.rewrites() {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		out, err := renderer.render(buf, doc, notes)
+		return writeRendered(w, out, err, p)
+	}
+	if p == nil {
+		bw := bufio.NewWriterSize(w, streamBufferSize)
+		renderer.flush = bw.Flush
+		renderer.write(bw, doc, notes)
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		return renderer.err
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bw := bufio.NewWriterSize(pw, streamBufferSize)
+		renderer.flush = bw.Flush
+		renderer.write(bw, doc, notes)
+		pw.CloseWithError(bw.Flush())
+	}()
+	err := p.SanitizeReaderToWriter(pr, w)
+	// Fail the remaining writes if the sanitizer stopped early.
+	pr.Close()
+	<-done
+	if err != nil {
+		return err
+	}
+	return renderer.err
+}
+
+// writeRendered writes the complete output out to w, sanitized with p
+// unless it's nil. It returns the error from writing to w, if any, or else
+// renderErr, the error from rendering out.
+func writeRendered(w io.Writer, out []byte, renderErr error, p *bluemonday.Policy) error {
+	var err error
+	if p == nil {
+		_, err = w.Write(out)
+	} else {
+		err = p.SanitizeReaderToWriter(bytes.NewReader(out), w)
+	}
+	if err != nil {
+		return err
+	}
+	return renderErr
+}
+
+// setContext sets the context of r to ctx, with the deadline of
+// r.opts.Timeout, if any. The returned function must be called when
+// rendering is done.
+func (r *renderer) setContext(ctx context.Context) (cancel func()) {
+	r.ctx = ctx
+	if r.opts.Timeout <= 0 {
+		return func() {}
+	}
+	// The deadline also stops highlighting, through r.ctx.
+	r.ctx, cancel = context.WithTimeout(ctx, r.opts.Timeout)
+	r.deadline, _ = r.ctx.Deadline()
+	return cancel
+}
+
+// render renders doc, parsed by parse, to unsanitized HTML, using buf for
+// the output. If r.ctx is done or r.deadline passes before rendering
+// completes, it returns the output rendered so far and r.ctx.Err() or
+// ErrTimeout.
+func (r *renderer) render(buf *bytes.Buffer, doc *bf.Node, notes *footnotes) ([]byte, error) {
+	r.write(buf, doc, notes)
+	return r.rewrite(buf.Bytes()), r.err
+}
+
+// write renders doc, parsed by parse, to w. If r.ctx is done, r.deadline
+// passes, or the output grows larger than r.opts.MaxOutputSize before
+// rendering completes, it stops and sets r.err.
+func (r *renderer) write(w io.Writer, doc *bf.Node, notes *footnotes) {
+	if max := r.opts.MaxOutputSize; max > 0 {
+		r.out = &limitedWriter{w: w, n: max, limit: max}
+		w = r.out
+	}
+	r.RenderHeader(w, doc)
+	if r.opts.TOC || r.opts.TOCMarker != "" {
+		entries := headings(doc, r.opts)
+		if r.opts.TOC {
+			writeTOC(w, entries)
+		}
+		if r.opts.TOCMarker != "" {
+			replaceTOCMarkers(doc, r.opts.TOCMarker, entries)
+		}
+	}
+	r.highlightAll(doc)
+	r.walk(w, doc)
+	if notes != nil && r.err == nil {
+		notes.render(w, r)
+	}
+	r.RenderFooter(w, doc)
+}
 
-	unsanitized := bf.Run(text, bf.WithRenderer(renderer), bf.WithExtensions(extensions))
-	sanitized := policy.SanitizeBytes(unsanitized)
-	return sanitized
+// rewrites reports whether the output of r is rewritten by rewrite.
+func (r *renderer) rewrites() bool {
+	return len(r.opts.IframeOrigins) > 0 || r.opts.SVG != SVGRemove || r.opts.DirAuto || r.opts.AMP
+}
+
+// rewrite returns the rendered output of r rewritten as enabled by r.opts,
+// which needs the complete output.
+func (r *renderer) rewrite(out []byte) []byte {
+	if len(r.opts.IframeOrigins) > 0 && iframeRE.Match(out) {
+		out = rewriteHTML(out, embedIframes(iframeOrigins(r.opts), r.opts.iframeSandbox()))
+	}
+	if r.opts.SVG != SVGRemove && svgRE.Match(out) {
+		out = rewriteHTML(out, r.svg)
+	}
+	if r.opts.DirAuto {
+		out = rewriteHTML(out, dirAuto)
+	}
+	if r.opts.AMP {
+		out = ampify(out)
+	}
+	return out
+}
+
+// parse parses text, applying the transforms enabled by r.opts to the
+// resulting document. It returns the document and its footnotes, if enabled.
+func (r *renderer) parse(text []byte) (*bf.Node, *footnotes) {
+	text = truncateInput(text, r.opts.maxInputSize())
+	if depth := r.opts.maxNestingDepth(); depth > 0 {
+		text = limitMarkers(text, depth)
+	}
+	if r.opts.FrontMatter {
+		text, _ = extractFrontMatter(text)
+	}
+
+	var notes *footnotes
+	if r.opts.Footnotes {
+		text, notes = extractFootnotes(text)
+	}
+	return r.parseDoc(text, notes), notes
+}
+
+// parseDoc parses text, with front matter and footnote definitions already
+// removed, applying the transforms enabled by r.opts to the resulting
+// document. It links footnote references to notes, if not nil.
+func (r *renderer) parseDoc(text []byte, notes *footnotes) *bf.Node {
+	var math inlineMath
+	if r.opts.Math {
+		text, math = extractMath(text)
+	}
+
+	doc := bf.New(bf.WithRenderer(r), bf.WithExtensions(r.opts.extensions())).Parse(text)
+	limitNesting(doc, r.opts.maxNestingDepth(), r.opts.maxNodes())
+	if r.opts.TagFilter {
+		tagFilter(doc)
+	}
+	detailsBlocks(doc)
+	if math != nil {
+		math.insert(doc, r)
+	}
+	if r.opts.Mode == CommentMode {
+		trimTrailingBreaks(doc)
+	}
+	autolinks(doc)
+	taskLists(doc)
+	rewriteURLs(doc, r.opts)
+	if r.opts.Mentions != nil {
+		mentions(doc, r.opts.Mentions)
+	}
+	if r.opts.References != nil {
+		linkReferences(doc, r.opts.References)
+	}
+	if r.opts.Emoji {
+		emoji(doc, r.opts.CustomEmoji)
+	}
+	if r.opts.Spoilers {
+		spoilers(doc)
+	}
+	if r.opts.RichTableCells {
+		richTableCells(doc)
+	}
+	if notes != nil {
+		notes.link(doc)
+	}
+	for _, transform := range r.opts.Transformers {
+		transform(doc)
+	}
+	return doc
 }
 
 // Heading returns a heading HTML node with title text.
@@ -51,13 +490,13 @@ func Markdown(text []byte) []byte {
 //
 // heading can be one of atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6.
 func Heading(heading atom.Atom, title string) *html.Node {
-	aName := sanitized_anchor_name.Create(title)
+	aName := slug(title)
 	a := &html.Node{
 		Type: html.ElementNode, Data: atom.A.String(),
 		Attr: []html.Attribute{
 			{Key: atom.Name.String(), Val: aName},
 			{Key: atom.Class.String(), Val: "anchor"},
-			{Key: atom.Href.String(), Val: "#" + aName},
+			{Key: atom.Href.String(), Val: anchorHref(aName)},
 			{Key: atom.Rel.String(), Val: "nofollow"},
 			{Key: "aria-hidden", Val: "true"},
 		},
@@ -76,28 +515,145 @@ func Heading(heading atom.Atom, title string) *html.Node {
 
 // extensions for GitHub Flavored Markdown-like parsing.
 const extensions = bf.NoIntraEmphasis |
-bf.Tables |
-bf.FencedCode |
-bf.Autolink |
-bf.Strikethrough |
-bf.SpaceHeadings |
-bf.NoEmptyLineBeforeBlock
+	bf.Tables |
+	bf.FencedCode |
+	bf.Autolink |
+	bf.Strikethrough |
+	bf.SpaceHeadings |
+	bf.NoEmptyLineBeforeBlock
 
 // policy for GitHub Flavored Markdown-like sanitization.
-var policy = func() *bluemonday.Policy {
+var policy = NewPolicy()
+
+// linkRelPolicy is the default policy for output rendered with
+// Options.LinkRel or Options.InternalLinkRel. It doesn't add nofollow.
+var linkRelPolicy = leaveLinkRel(NewPolicy())
+
+// leaveLinkRel makes p leave the rel of links to the renderer, except for
+// noreferrer, which it still adds to links with a host. bluemonday only
+// adds noopener to links with target="_blank" while it requires a rel token,
+// and links in raw HTML can have that target.
+func leaveLinkRel(p *bluemonday.Policy) *bluemonday.Policy {
+	return p.RequireNoFollowOnLinks(false).RequireNoReferrerOnFullyQualifiedLinks(true)
+}
+
+// NewPolicy returns a new copy of the policy used to sanitize output by default.
+// It can be extended and used with WithPolicy, for example to allow
+// additional elements:
+//
+//	p := github_flavored_markdown.NewPolicy()
+//	p.AllowElements("figure", "figcaption")
+//	html := github_flavored_markdown.Markdown(text, github_flavored_markdown.WithPolicy(p))
+func NewPolicy() *bluemonday.Policy {
+	p := newPolicy()
+	p.AllowDataURIImages()
+	return p
+}
+
+// newPolicy returns the default policy without data URI images.
+func newPolicy() *bluemonday.Policy {
 	p := bluemonday.UGCPolicy()
 	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("div", "span")
 	p.AllowAttrs("class", "name").Matching(bluemonday.SpaceSeparatedTokens).OnElements("a")
-	p.AllowAttrs("rel").Matching(regexp.MustCompile(`^nofollow$`)).OnElements("a")
+	p.AllowAttrs("name").Matching(regexp.MustCompile(`^[\p{L}\p{M}\p{N}_-]+$`)).OnElements("a")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{M}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("rel").Matching(regexp.MustCompile(`^(nofollow|noopener|noreferrer|sponsored|ugc)( (nofollow|noopener|noreferrer|sponsored|ugc))*$`)).OnElements("a")
+	p.AllowAttrs("target").Matching(regexp.MustCompile(`^_blank$`)).OnElements("a")
 	p.AllowAttrs("aria-hidden").Matching(regexp.MustCompile(`^true$`)).OnElements("a")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^footnotes$`)).OnElements("section")
+	p.AllowAttrs("data-footnotes").Matching(regexp.MustCompile(`^$`)).OnElements("section")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^sr-only$`)).OnElements("h2")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^markdown-alert-title$`)).OnElements("p")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^mermaid$`)).OnElements("img")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(mermaid|language-[\w+#.-]+)$`)).OnElements("pre")
+	p.AllowAttrs("data-type").Matching(regexp.MustCompile(`^[a-z0-9-]+$`)).OnElements("div")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^toc$`)).OnElements("nav")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^user-content-fn(ref)?-[\p{L}\p{N}_-]+$`)).OnElements("a", "li")
+	p.AllowAttrs("data-footnote-ref", "data-footnote-backref").Matching(regexp.MustCompile(`^$`)).OnElements("a")
+	p.AllowAttrs("aria-describedby").Matching(regexp.MustCompile(`^footnote-label$`)).OnElements("a")
+	p.AllowAttrs("aria-label").Matching(regexp.MustCompile(`^Back to reference [0-9]+(-[0-9]+)?$`)).OnElements("a")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^emoji$`)).OnElements("img")
+	p.AllowAttrs("alt", "title").Matching(regexp.MustCompile(`^:[\w+-]+:$`)).OnElements("img")
+	p.AllowAttrs("dir").Matching(regexp.MustCompile(`(?i)^(auto|ltr|rtl)$`)).Globally()
 	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
 	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
-	p.AllowDataURIImages()
+	p.AllowElements("details", "summary")
+	p.AllowAttrs("open").Matching(regexp.MustCompile(`(?i)^(|open)$`)).OnElements("details")
 	return p
-}()
+}
+
+// allowCopyAnchors extends p to allow the heading anchor buttons of
+// Options.CopyableHeadingAnchors.
+func allowCopyAnchors(p *bluemonday.Policy) {
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^copy-anchor$`)).OnElements("button")
+	p.AllowAttrs("data-anchor").Matching(regexp.MustCompile(`^#[\p{L}\p{M}\p{N}_-]*$`)).OnElements("button")
+	p.AllowAttrs("aria-label").Matching(regexp.MustCompile(`^Copy link$`)).OnElements("button")
+}
 
 type renderer struct {
 	*bf.HTMLRenderer
+	opts    Options
+	anchors anchorNamer
+
+	highlighted map[*bf.Node]*highlightResult // By code block, set by highlightAll.
+
+	ctx      context.Context
+	deadline time.Time // Zero if there is no timeout.
+	err      error     // ctx.Err(), ErrTimeout, or the error of flush or out, if rendering was stopped.
+
+	flush func() error   // Called by walk before each top-level block, if set.
+	out   *limitedWriter // Output, if Options.MaxOutputSize is set.
+}
+
+func newRenderer(opts Options) *renderer {
+	params := bf.HTMLRendererParameters{
+		Flags:              opts.htmlFlags(),
+		HeadingLevelOffset: opts.HeadingLevelOffset,
+	}
+
+	return &renderer{
+		HTMLRenderer: bf.NewHTMLRenderer(params),
+		opts:         opts,
+		anchors:      newAnchorNamer(opts),
+		ctx:          context.Background(),
+	}
+}
+
+// walk renders the nodes of doc to w, calling r.flush, if set, before each
+// top-level block. It stops before the next top-level block if r.ctx is
+// done or r.flush fails, before the next node if r.out is full, and before
+// the next node, closing the open elements, if r.deadline has passed.
+// It sets r.err when it stops.
+func (r *renderer) walk(w io.Writer, doc *bf.Node) {
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering {
+			return r.RenderNode(w, node, entering)
+		}
+		if r.out != nil && r.out.err != nil {
+			r.err = r.out.err
+			return bf.Terminate
+		}
+		if !r.deadline.IsZero() && !time.Now().Before(r.deadline) {
+			r.err = ErrTimeout
+			for open := node.Parent; open != doc; open = open.Parent {
+				r.RenderNode(w, open, false)
+			}
+			return bf.Terminate
+		}
+		if node.Parent == doc {
+			if r.flush != nil {
+				if err := r.flush(); err != nil {
+					r.err = err
+					return bf.Terminate
+				}
+			}
+			if err := r.ctx.Err(); err != nil {
+				r.err = err
+				return bf.Terminate
+			}
+		}
+		return r.RenderNode(w, node, entering)
+	})
 }
 
 func appendLanguageAttr(attrs []string, info []byte) []string {
@@ -110,7 +666,7 @@ func appendLanguageAttr(attrs []string, info []byte) []string {
 	}
 
 	// try to get the end of the language
-	endOfLang := bytes.IndexAny(info, "\t ")
+	endOfLang := bytes.IndexAny(info, "\t {:")
 	if endOfLang < 0 {
 		// if it's not found, just use the whole thing
 		endOfLang = len(info)
@@ -121,54 +677,167 @@ func appendLanguageAttr(attrs []string, info []byte) []string {
 }
 
 func findLang(info []byte) []byte {
-	endOfLang := bytes.IndexAny(info, "\t ")
+	endOfLang := bytes.IndexAny(info, "\t {:")
 	if endOfLang < 0 {
-		return []byte("")
+		// if it's not found, just use the whole thing
+		endOfLang = len(info)
 	}
 
 	return info[:endOfLang]
 }
 
-func heading(w io.Writer, node *bf.Node, entering bool) bf.WalkStatus {
+// findTitle returns the title of a code block given in its info string,
+// either as "go:main.go" or "go title=main.go". The title may be quoted.
+func findTitle(info []byte) []byte {
+	lang := findLang(info)
+	rest := info[len(lang):]
+	if bytes.HasPrefix(rest, []byte(":")) {
+		return firstField(rest[1:])
+	}
+	for {
+		rest = bytes.TrimLeft(rest, "\t ")
+		if bytes.HasPrefix(rest, []byte("title=")) {
+			return firstField(rest[len("title="):])
+		}
+		end := bytes.IndexAny(rest, "\t ")
+		if end < 0 {
+			return nil
+		}
+		rest = rest[end:]
+	}
+}
+
+// firstField returns the leading field of b, ending at whitespace or a brace.
+// A field starting with a double quote extends to the closing quote, and is
+// returned unquoted.
+func firstField(b []byte) []byte {
+	if bytes.HasPrefix(b, []byte(`"`)) {
+		if end := bytes.IndexByte(b[1:], '"'); end >= 0 {
+			return b[1 : 1+end]
+		}
+	}
+	if end := bytes.IndexAny(b, "\t {"); end >= 0 {
+		return b[:end]
+	}
+	return b
+}
+
+func (r *renderer) heading(w io.Writer, node *bf.Node, entering bool) bf.WalkStatus {
+	level := r.headingLevel(node)
+	if !entering {
+		// Close the heading through HTMLRenderer, which tracks the output
+		// that the spacing before the next block depends on.
+		return r.HTMLRenderer.RenderNode(w, node, entering)
+	}
+
 	if node.Prev != nil {
 		w.Write([]byte("\n"))
 	}
 
-	// Extract text content of the heading.
-	var textContent string
-	if htmlNode, err := html.Parse(bytes.NewReader(node.Literal)); err == nil {
-		textContent = extractText(htmlNode)
-	} else {
-		// Failed to parse HTML (probably can never happen), so just use the whole thing.
-		textContent = html.UnescapeString(string(node.Literal))
+	anchorName := r.anchors.name(headingText(node))
+	href := anchorName
+	if r.opts.GitHubAnchors {
+		// GitHub links to the anchor without its prefix.
+		href = strings.TrimPrefix(anchorName, userContentPrefix)
+	}
+
+	var id string
+	if r.opts.HeadingIDs {
+		id = fmt.Sprintf(` id="%s"`, html.EscapeString(anchorName))
 	}
-	anchorName := sanitized_anchor_name.Create(textContent)
 
-	w.Write([]byte(fmt.Sprintf(`<h%d><a name="%s" class="anchor" href="#%s" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>`, node.HeadingData.Level, anchorName, anchorName)))
-	//w.Write([]textHTML)
-	w.Write([]byte(fmt.Sprintf("</h%d>\n", node.HeadingData.Level)))
+	switch {
+	case r.opts.NoHeadingAnchors:
+		w.Write([]byte(fmt.Sprintf(`<h%d%s>`, level, id)))
+	case r.opts.CopyableHeadingAnchors:
+		w.Write([]byte(fmt.Sprintf(`<h%d%s><button class="copy-anchor" data-anchor="#%s" aria-label="Copy link"><span class="octicon octicon-link"></span></button>`, level, id, html.EscapeString(href))))
+	case r.opts.HeadingIDs:
+		w.Write([]byte(fmt.Sprintf(`<h%d%s><a class="anchor" href="%s"%s aria-hidden="true"><span class="octicon octicon-link"></span></a>`, level, id, anchorHref(href), relAttr(r.rel(false)))))
+	default:
+		w.Write([]byte(fmt.Sprintf(`<h%d><a name="%s" class="anchor" href="%s"%s aria-hidden="true"><span class="octicon octicon-link"></span></a>`, level, html.EscapeString(anchorName), anchorHref(href), relAttr(r.rel(false)))))
+	}
 
 	return bf.GoToNext
 }
 
-func codeblock(w io.Writer, node *bf.Node, entering bool) bf.WalkStatus {
+// headingLevel returns the level of the heading element for node, offset by
+// r.opts.HeadingLevelOffset.
+func (r *renderer) headingLevel(node *bf.Node) int {
+	level := node.HeadingData.Level + r.opts.HeadingLevelOffset
+	switch {
+	case level < 1:
+		return 1
+	case level > 6:
+		return 6
+	}
+	return level
+}
+
+func (r *renderer) codeblock(w io.Writer, node *bf.Node, entering bool) bf.WalkStatus {
 	//r.cr(w)
 
-	// parse out language
-	lang := findLang(node.Info)
+	lang := r.codeLang(node)
+	if r.opts.Math && string(lang) == "math" {
+		w.Write(r.renderMath(string(bytes.TrimSuffix(node.Literal, []byte("\n"))), true))
+		return bf.GoToNext
+	}
+	if r.opts.Mermaid && string(lang) == "mermaid" {
+		r.mermaid(w, node.Literal)
+		return bf.GoToNext
+	}
+	if f, ok := r.opts.Fences[string(lang)]; ok {
+		if out, ok := f.RenderFence(node.Literal, string(lang)); ok {
+			w.Write(out)
+			return bf.GoToNext
+		}
+	}
 
 	if len(lang) == 0 {
 		w.Write([]byte(`<pre><code>`))
 	} else {
 		// <div class="highlight highlight-...">
-		w.Write([]byte(fmt.Sprintf(`<div class="highlight highlight-%s">`, lang)))
+		w.Write([]byte(fmt.Sprintf(`<div class="highlight highlight-%s`, lang)))
+		if s := r.opts.CodeClasses; s != nil && s.Block != "" {
+			w.Write([]byte(" "))
+			attrEscape(w, []byte(s.Block))
+		}
+		w.Write([]byte(`">`))
+		if title := findTitle(node.Info); len(title) > 0 {
+			w.Write([]byte(`<div class="highlight-title">`))
+			attrEscape(w, title)
+			w.Write([]byte(`</div>`))
+		}
+		if r.opts.CodeLangBadge {
+			w.Write([]byte(`<span class="lang-badge">`))
+			attrEscape(w, lang)
+			w.Write([]byte(`</span>`))
+		}
+		if s := r.opts.CodeClasses; s != nil && s.Language {
+			w.Write([]byte(`<pre class="language-`))
+			attrEscape(w, lang)
+			w.Write([]byte(`">`))
+		} else {
+			w.Write([]byte(`<pre>`))
+		}
 	}
 
-	if highlightedCode, ok := highlightCode(node.Literal, string(lang)); ok {
-		w.Write(highlightedCode)
+	var code []byte
+	var ok bool
+	if result := r.highlighted[node]; result != nil {
+		code, ok = result.code, result.ok
 	} else {
-		attrEscape(w, node.Literal)
+		code, ok = r.highlight(node.Literal, string(lang))
+	}
+	if !ok {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		attrEscape(buf, node.Literal)
+		code = buf.Bytes()
+	}
+	if highlight := parseLineRanges(node.Info); r.opts.LineNumbers || highlight != nil {
+		code = formatLines(code, r.opts.LineNumbers, highlight)
 	}
+	w.Write(code)
 
 	if len(lang) == 0 {
 		w.Write([]byte(`</code></pre>`))
@@ -187,37 +856,72 @@ func codeblock(w io.Writer, node *bf.Node, entering bool) bf.WalkStatus {
 func (r *renderer) RenderNode(w io.Writer, node *bf.Node, entering bool) bf.WalkStatus {
 	switch node.Type {
 	case bf.Heading:
-		return heading(w, node, entering)
+		return r.heading(w, node, entering)
 
 	case bf.CodeBlock:
-		return codeblock(w, node, entering)
+		return r.codeblock(w, node, entering)
+
+	case bf.BlockQuote:
+		return r.blockquote(w, node, entering)
+
+	case bf.Link:
+		if node.NoteID == 0 && (r.opts.ExternalLinksNewTab || r.opts.LinkRel != nil || r.opts.InternalLinkRel != nil) {
+			return r.link(w, node, entering)
+		}
 	}
 
-	return bf.GoToNext
+	return r.HTMLRenderer.RenderNode(w, node, entering)
 }
 
-// extractText returns the recursive concatenation of the text content of an html node.
-func extractText(n *html.Node) string {
-	var out string
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if c.Type == html.TextNode {
-			out += c.Data
-		} else {
-			out += extractText(c)
+// headingText returns the plain text content of a heading node,
+// the recursive concatenation of its text and code span literals.
+func headingText(node *bf.Node) string {
+	var out []byte
+	node.Walk(func(n *bf.Node, entering bool) bf.WalkStatus {
+		if entering && (n.Type == bf.Text || n.Type == bf.Code) {
+			out = append(out, n.Literal...)
 		}
+		return bf.GoToNext
+	})
+	return html.UnescapeString(string(out))
+}
+
+// codeLang returns the canonical language of the code block node.
+func (r *renderer) codeLang(node *bf.Node) []byte {
+	// parse out language
+	lang := findLang(node.Info)
+	if len(lang) == 0 && node.IsFenced {
+		lang = []byte(r.opts.DefaultCodeLang)
 	}
-	return out
+	if len(lang) > 0 {
+		lang = []byte(canonicalLang(string(lang), r.opts))
+	}
+	return lang
 }
 
-// Task List support.
-func (r *renderer) ListItem(out *bytes.Buffer, text []byte, flags int) {
-	switch {
-	case bytes.HasPrefix(text, []byte("[ ] ")):
-		text = append([]byte(`<input type="checkbox" disabled="">`), text[3:]...)
-	case bytes.HasPrefix(text, []byte("[x] ")) || bytes.HasPrefix(text, []byte("[X] ")):
-		text = append([]byte(`<input type="checkbox" checked="" disabled="">`), text[3:]...)
+// highlight highlights src with the configured highlighter, falling back
+// to the built-in highlighters. It reports false if src is too large to
+// highlight.
+func (r *renderer) highlight(src []byte, lang string) ([]byte, bool) {
+	if !r.opts.highlightable(src) {
+		return nil, false
 	}
-	r.HTMLRenderer.ListItem(out, text, flags)
+	if r.opts.Highlighter != nil {
+		if out, ok := r.opts.Highlighter.Highlight(src, lang); ok {
+			return out, true
+		}
+	}
+	return r.highlightBuiltin(src, lang)
+}
+
+// highlightBuiltin highlights src in language lang with the built-in
+// highlighters. It's safe for concurrent use.
+func (r *renderer) highlightBuiltin(src []byte, lang string) ([]byte, bool) {
+	out, ok := highlightCode(r.ctx, src, lang)
+	if ok && r.opts.CodeClasses != nil {
+		out = r.opts.CodeClasses.rename(out)
+	}
+	return out, ok
 }
 
 var gfmHTMLConfig = syntaxhighlight.HTMLConfig{
@@ -235,9 +939,11 @@ var gfmHTMLConfig = syntaxhighlight.HTMLConfig{
 	Decimal:       "m",
 }
 
-func highlightCode(src []byte, lang string) (highlightedCode []byte, ok bool) {
-	switch lang {
-	case "Go", "Go-unformatted":
+// highlightCode highlights src in language lang. It reports false if lang
+// isn't supported, or if ctx is done before highlighting a diff completes.
+func highlightCode(ctx context.Context, src []byte, lang string) (highlightedCode []byte, ok bool) {
+	switch normalizeLang(lang) {
+	case "go", "go-unformatted":
 		var buf bytes.Buffer
 		err := highlight_go.Print(src, &buf, syntaxhighlight.HTMLPrinter(gfmHTMLConfig))
 		if err != nil {
@@ -260,6 +966,9 @@ func highlightCode(src []byte, lang string) (highlightedCode []byte, ok bool) {
 
 		lastDel, lastIns := -1, -1
 		for lineIndex := 0; lineIndex < len(lines); lineIndex++ {
+			if ctx.Err() != nil {
+				return nil, false
+			}
 			var lineFirstChar byte
 			if len(lines[lineIndex]) > 0 {
 				lineFirstChar = lines[lineIndex][0]
@@ -321,8 +1030,12 @@ func highlightCode(src []byte, lang string) (highlightedCode []byte, ok bool) {
 			return nil, false
 		}
 		return out, true
+	case "makefile", "make", "mk":
+		return highlightMakefile(src), true
+	case "cmake":
+		return highlightCMake(src), true
 	default:
-		return nil, false
+		return highlightChroma(src, lang)
 	}
 }
 
@@ -334,35 +1047,35 @@ func doubleSpace(out *bytes.Buffer) {
 	}
 }
 
-func escapeSingleChar(char byte) (string, bool) {
-	if char == '"' {
-		return "&quot;", true
-	}
-	if char == '&' {
-		return "&amp;", true
-	}
-	if char == '<' {
-		return "&lt;", true
-	}
-	if char == '>' {
-		return "&gt;", true
-	}
-	return "", false
+// attrEntities are the entities of the characters escaped by attrEscape,
+// indexed by character.
+var attrEntities = [256]string{
+	'"': "&quot;",
+	'&': "&amp;",
+	'<': "&lt;",
+	'>': "&gt;",
 }
 
+// attrEscape writes src to w with the characters that are special in HTML
+// text and attribute values escaped. The runs of characters between them
+// are written unchanged, at once.
 func attrEscape(w io.Writer, src []byte) {
+	buf, ok := w.(*bytes.Buffer)
+	if !ok {
+		buf = getBuffer()
+		defer putBuffer(buf)
+	}
+	buf.Grow(len(src))
 	org := 0
 	for i, ch := range src {
-		if entity, ok := escapeSingleChar(ch); ok {
-			if i > org {
-				// copy all the normal characters since the last escape
-				w.Write(src[org:i])
-			}
+		if entity := attrEntities[ch]; len(entity) > 0 {
+			buf.Write(src[org:i])
+			buf.WriteString(entity)
 			org = i + 1
-			w.Write(entity)
 		}
 	}
-	if org < len(src) {
-		w.Write(src[org:])
+	buf.Write(src[org:])
+	if !ok {
+		w.Write(buf.Bytes())
 	}
 }
@@ -1,10 +1,15 @@
 package github_flavored_markdown_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/shurcooL/github_flavored_markdown"
 	"github.com/shurcooL/github_flavored_markdown/gfmstyle"
@@ -82,6 +87,22 @@ func TestComponents(t *testing.T) {
 </ul>
 `,
 		},
+		{
+			// Intra-word underscores are not emphasis.
+			text: "a_b_c and foo_bar_baz",
+			want: `<p>a_b_c and foo_bar_baz</p>` + "\n",
+		},
+		{
+			// Nested emphasis.
+			text: "**_x_**",
+			want: `<p><strong><em>x</em></strong></p>` + "\n",
+		},
+		{
+			// Triple emphasis. GitHub renders <em><strong>x</strong></em>, which is equivalent.
+			// blackfriday parses it to the same tree as **_x_**, so the nesting order can't be told apart.
+			text: "***x***",
+			want: `<p><strong><em>x</em></strong></p>` + "\n",
+		},
 	}
 
 	for _, test := range tests {
@@ -91,6 +112,205 @@ func TestComponents(t *testing.T) {
 	}
 }
 
+func TestMarkdownTo(t *testing.T) {
+	text := []byte("# Title\n\nHello <script>alert();</script> **world**.\n")
+
+	var buf bytes.Buffer
+	if err := github_flavored_markdown.MarkdownTo(&buf, text); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), string(github_flavored_markdown.Markdown(text)); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	if err := github_flavored_markdown.MarkdownTo(errWriter{}, text); err == nil {
+		t.Error("got nil error, want write error")
+	}
+}
+
+func TestMarkdownToChunks(t *testing.T) {
+	text := []byte("# Title\n\nFirst **paragraph**.\n\nSecond paragraph.\n")
+
+	for _, opts := range [][]github_flavored_markdown.Option{nil, {github_flavored_markdown.Unsafe()}} {
+		// The output is written after each top-level block.
+		var w chunkWriter
+		if err := github_flavored_markdown.MarkdownTo(&w, text, opts...); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := w.String(), string(github_flavored_markdown.Markdown(text, opts...)); got != want {
+			t.Errorf("\ngot %q\nwant %q", got, want)
+		}
+		if len(w.chunks) < 3 {
+			t.Errorf("got %d chunks %q, want at least 3", len(w.chunks), w.chunks)
+		}
+	}
+}
+
+// chunkWriter is a bytes.Buffer that records the chunks written to it.
+type chunkWriter struct {
+	bytes.Buffer
+	chunks []string
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, string(p))
+	return w.Buffer.Write(p)
+}
+
+func (w *chunkWriter) WriteString(s string) (int, error) {
+	w.chunks = append(w.chunks, s)
+	return w.Buffer.WriteString(s)
+}
+
+func TestMarkdownReader(t *testing.T) {
+	text := "Hello **world**.\n"
+
+	got, err := github_flavored_markdown.MarkdownReader(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := github_flavored_markdown.Markdown([]byte(text)); !bytes.Equal(got, want) {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	if _, err := github_flavored_markdown.MarkdownReader(strings.NewReader(text), github_flavored_markdown.WithMaxInputSize(int64(len(text)))); err != nil {
+		t.Errorf("input at the limit: got error %v", err)
+	}
+	_, err = github_flavored_markdown.MarkdownReader(strings.NewReader(text), github_flavored_markdown.WithMaxInputSize(5))
+	if _, ok := err.(*github_flavored_markdown.InputTooLargeError); !ok {
+		t.Errorf("input over the limit: got error %v, want *InputTooLargeError", err)
+	}
+}
+
+func TestMaxInputSize(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int64
+		want string
+	}{
+		{
+			// Cut after the last full line.
+			text: "line one\nline two\n",
+			max:  12,
+			want: "<p>line one</p>\n",
+		},
+		{
+			// Cut at a rune boundary.
+			text: "héllo",
+			max:  2,
+			want: "<p>h</p>\n",
+		},
+		{
+			text: "line one\nline two\n",
+			max:  -1,
+			want: "<p>line one\nline two</p>\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithMaxInputSize(test.max))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+
+	text := []byte(strings.Repeat("a", github_flavored_markdown.DefaultMaxInputSize+1))
+	_, err := github_flavored_markdown.MarkdownContext(context.Background(), text)
+	if err, ok := err.(*github_flavored_markdown.InputTooLargeError); !ok || err.Limit != github_flavored_markdown.DefaultMaxInputSize {
+		t.Errorf("input over the default limit: got error %v, want *InputTooLargeError", err)
+	}
+	if err := github_flavored_markdown.MarkdownTo(io.Discard, []byte("line one\n"), github_flavored_markdown.WithMaxInputSize(5)); err == nil {
+		t.Error("MarkdownTo: got nil error, want *InputTooLargeError")
+	}
+}
+
+func TestMarkdownContext(t *testing.T) {
+	text := []byte("# Title\n\nHello **world**.\n")
+
+	got, err := github_flavored_markdown.MarkdownContext(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := github_flavored_markdown.Markdown(text); !bytes.Equal(got, want) {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := github_flavored_markdown.MarkdownContext(ctx, text); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestMarkdownContextPartial(t *testing.T) {
+	text := []byte("First.\n\nSecond.\n\nThird.\n")
+
+	// Done after the first top-level block is rendered.
+	ctx := &countdownContext{Context: context.Background(), n: 2}
+	got, err := github_flavored_markdown.MarkdownContext(ctx, text)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if want := "<p>First.</p>\n"; string(got) != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	// A highlighter that takes longer than the timeout.
+	slow := github_flavored_markdown.HighlighterFunc(func(src []byte, lang string) ([]byte, bool) {
+		time.Sleep(50 * time.Millisecond)
+		return nil, false
+	})
+	text := []byte("> a\n>\n> ```slow\n> b\n> ```\n>\n> c\n\nd\n")
+
+	// Stopped after the code block, with the block quote closed.
+	got, err := github_flavored_markdown.MarkdownContext(context.Background(), text,
+		github_flavored_markdown.WithHighlighter(slow), github_flavored_markdown.WithTimeout(10*time.Millisecond))
+	if err != github_flavored_markdown.ErrTimeout {
+		t.Errorf("got error %v, want %v", err, github_flavored_markdown.ErrTimeout)
+	}
+	if want := "<blockquote>\n<p>a</p>\n<div class=\"highlight highlight-slow\"><pre>b\n</pre></div></blockquote>\n"; string(got) != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	err = github_flavored_markdown.MarkdownTo(&buf, text,
+		github_flavored_markdown.WithHighlighter(slow), github_flavored_markdown.WithTimeout(10*time.Millisecond))
+	if err != github_flavored_markdown.ErrTimeout {
+		t.Errorf("MarkdownTo: got error %v, want %v", err, github_flavored_markdown.ErrTimeout)
+	}
+	if !bytes.Equal(buf.Bytes(), got) {
+		t.Errorf("MarkdownTo:\ngot %q\nwant %q", buf.Bytes(), got)
+	}
+
+	// Rendering that completes in time is not affected.
+	text = []byte("- a\n- b\n")
+	got, err = github_flavored_markdown.MarkdownContext(context.Background(), text, github_flavored_markdown.WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := github_flavored_markdown.Markdown(text); !bytes.Equal(got, want) {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+// countdownContext is a context that is done after Err has been called n times.
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n--; c.n < 0 {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("write error") }
+
 func ExampleHeading() {
 	heading := github_flavored_markdown.Heading(atom.H2, "Hello > Goodbye")
 	html.Render(os.Stdout, heading)
@@ -98,3 +318,62 @@ func ExampleHeading() {
 	// Output:
 	// <h2><a name="hello-goodbye" class="anchor" href="#hello-goodbye" rel="nofollow" aria-hidden="true"><span class="octicon-link"><svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 16 16" style="fill: currentColor; vertical-align: top;"><path d="M4 9h1v1H4c-1.5 0-3-1.69-3-3.5S2.55 3 4 3h4c1.45 0 3 1.69 3 3.5 0 1.41-.91 2.72-2 3.25V8.59c.58-.45 1-1.27 1-2.09C10 5.22 8.98 4 8 4H4c-.98 0-2 1.22-2 2.5S3 9 4 9zm9-3h-1v1h1c1 0 2 1.22 2 2.5S13.98 12 13 12H9c-.98 0-2-1.22-2-2.5 0-.83.42-1.64 1-2.09V6.25c-1.09.53-2 1.84-2 3.25C6 11.31 7.55 13 9 13h4c1.45 0 3-1.69 3-3.5S14.5 6 13 6z"></path></svg></span></a>Hello &gt; Goodbye</h2>
 }
+
+var benchmarkText = []byte(`# GitHub Flavored Markdown
+
+Some **bold** and _italic_ text, with a [link](https://example.com) and ` + "`code`" + `.
+
+- [ ] An incomplete task.
+- [x] A done task.
+
+| Name | Value |
+|------|-------|
+| a    | 1     |
+
+` + "```" + `
+func main() {
+	fmt.Println("Hello, <world>!")
+}
+` + "```" + `
+`)
+
+func BenchmarkMarkdown(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		github_flavored_markdown.Markdown(benchmarkText)
+	}
+}
+
+func BenchmarkMarkdownTrusted(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		github_flavored_markdown.MarkdownTrusted(benchmarkText)
+	}
+}
+
+func BenchmarkMarkdownLarge(b *testing.B) {
+	text := bytes.Repeat(benchmarkText, 500)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		github_flavored_markdown.Markdown(text)
+	}
+}
+
+// BenchmarkMarkdownParallel renders many short comments concurrently,
+// like a server does.
+func BenchmarkMarkdownParallel(b *testing.B) {
+	text := []byte("Thanks @octocat, this **looks good** to me. See [the docs](https://example.com/docs).\n\n- [x] Tests\n- [ ] Changelog\n")
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			github_flavored_markdown.Markdown(text)
+		}
+	})
+}
+
+func BenchmarkMarkdownTo(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		github_flavored_markdown.MarkdownTo(io.Discard, benchmarkText)
+	}
+}
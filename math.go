@@ -0,0 +1,257 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"text/template"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// MathRenderer renders TeX math expressions, for example with KaTeX.
+type MathRenderer interface {
+	// RenderMath returns tex rendered as HTML, as a block if display is set.
+	// It reports false if it can't render tex, in which case the default
+	// markup is used.
+	RenderMath(tex string, display bool) ([]byte, bool)
+}
+
+// MathRendererFunc is an adapter to allow the use of ordinary functions as MathRenderers.
+type MathRendererFunc func(tex string, display bool) ([]byte, bool)
+
+// RenderMath calls f(tex, display).
+func (f MathRendererFunc) RenderMath(tex string, display bool) ([]byte, bool) {
+	return f(tex, display)
+}
+
+// Inline math is replaced with placeholders before parsing, so that
+// blackfriday doesn't parse its contents. They use private use characters,
+// which don't occur in Markdown syntax.
+const (
+	mathPlaceholderStart = "\uE000"
+	mathPlaceholderEnd   = "\uE001"
+)
+
+var mathPlaceholderRE = regexp.MustCompile(`(` + mathPlaceholderStart + `([0-9]+)` + mathPlaceholderEnd + `)`)
+
+// inlineMath holds the inline math expressions extracted from the source.
+type inlineMath []string
+
+// extractMath replaces inline math, "$tex$", in text with placeholders,
+// and converts display math blocks, lines of "$$" around TeX or a line
+// like "$$tex$$", into math code blocks. Code blocks and spans are skipped.
+// An inline expression must not start or end with a space, must not be
+// followed by a digit, so that "$5 and $6" isn't math, and must not contain
+// a backtick.
+func extractMath(text []byte) ([]byte, inlineMath) {
+	var math inlineMath
+	var out [][]byte
+	var fence []byte
+	lines := bytes.Split(text, []byte("\n"))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := fenceMarker(line); m != nil {
+			switch {
+			case fence == nil:
+				fence = m
+			case bytes.HasPrefix(m, fence):
+				fence = nil
+			}
+		}
+		if fence != nil || fenceMarker(line) != nil {
+			out = append(out, line)
+			continue
+		}
+
+		if tex, ok := displayMathLine(line); ok {
+			out = append(out, []byte("```math"), tex, []byte("```"))
+			continue
+		}
+		if isDisplayMathDelimiter(line) {
+			end := i + 1
+			for end < len(lines) && !isDisplayMathDelimiter(lines[end]) {
+				end++
+			}
+			if end < len(lines) {
+				out = append(out, []byte("```math"))
+				out = append(out, lines[i+1:end]...)
+				out = append(out, []byte("```"))
+				i = end
+				continue
+			}
+		}
+
+		out = append(out, math.extractInline(line))
+	}
+	if math == nil {
+		return bytes.Join(out, []byte("\n")), nil
+	}
+	return bytes.Join(out, []byte("\n")), math
+}
+
+// isDisplayMathDelimiter reports whether line is "$$", indented by up to 3 spaces.
+func isDisplayMathDelimiter(line []byte) bool {
+	trimmed := bytes.TrimLeft(line, " ")
+	return len(line)-len(trimmed) <= 3 && bytes.Equal(bytes.TrimRight(trimmed, " \t"), []byte("$$"))
+}
+
+// displayMathLine returns the TeX of a line like "$$tex$$".
+func displayMathLine(line []byte) ([]byte, bool) {
+	trimmed := bytes.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return nil, false
+	}
+	trimmed = bytes.TrimRight(trimmed, " \t")
+	if len(trimmed) <= 4 || !bytes.HasPrefix(trimmed, []byte("$$")) || !bytes.HasSuffix(trimmed, []byte("$$")) {
+		return nil, false
+	}
+	tex := trimmed[2 : len(trimmed)-2]
+	if bytes.Contains(tex, []byte("$$")) {
+		return nil, false
+	}
+	return tex, true
+}
+
+// extractInline replaces the inline math in line with placeholders,
+// appending the expressions to math.
+func (math *inlineMath) extractInline(line []byte) []byte {
+	if bytes.IndexByte(line, '$') < 0 {
+		return line
+	}
+	var out []byte
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line):
+			out = append(out, line[i:i+2]...)
+			i += 2
+		case c == '`':
+			n := codeSpanEnd(line, i)
+			out = append(out, line[i:n]...)
+			i = n
+		case c == '$':
+			end := inlineMathEnd(line, i)
+			if end < 0 {
+				out = append(out, c)
+				i++
+				if i < len(line) && line[i] == '$' {
+					out = append(out, '$')
+					i++
+				}
+				continue
+			}
+			out = append(out, fmt.Sprintf(mathPlaceholderStart+"%d"+mathPlaceholderEnd, len(*math))...)
+			*math = append(*math, string(line[i+1:end]))
+			i = end + 1
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out
+}
+
+// codeSpanEnd returns the index after the code span starting with the
+// backtick at line[start], or after the run of backticks if it's unclosed.
+func codeSpanEnd(line []byte, start int) int {
+	n := start
+	for n < len(line) && line[n] == '`' {
+		n++
+	}
+	run := line[start:n]
+	for i := n; i < len(line); {
+		j := bytes.Index(line[i:], run)
+		if j < 0 {
+			break
+		}
+		j += i
+		k := j + len(run)
+		if k == len(line) || line[k] != '`' {
+			return k
+		}
+		for k < len(line) && line[k] == '`' {
+			k++
+		}
+		i = k
+	}
+	return n
+}
+
+// inlineMathEnd returns the index of the "$" closing the inline math
+// opened by the "$" at line[start], or -1 if it isn't inline math.
+func inlineMathEnd(line []byte, start int) int {
+	if start+1 >= len(line) || line[start+1] == ' ' || line[start+1] == '\t' || line[start+1] == '$' {
+		return -1
+	}
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '`':
+			// Code spans take precedence.
+			return -1
+		case '$':
+			if line[i-1] == ' ' || line[i-1] == '\t' {
+				return -1
+			}
+			if i+1 < len(line) && (line[i+1] == '$' || '0' <= line[i+1] && line[i+1] <= '9') {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// insert replaces the placeholders in the text of doc with the rendered
+// inline math. Placeholders elsewhere, such as in code blocks or URLs,
+// are restored to the original source.
+func (math inlineMath) insert(doc *bf.Node, r *renderer) {
+	expr := func(digits []byte) (string, bool) {
+		i, err := strconv.Atoi(string(digits))
+		if err != nil || i >= len(math) {
+			return "", false
+		}
+		return math[i], true
+	}
+	restore := func(b []byte) []byte {
+		return mathPlaceholderRE.ReplaceAllFunc(b, func(p []byte) []byte {
+			tex, _ := expr(mathPlaceholderRE.FindSubmatch(p)[2])
+			return []byte("$" + tex + "$")
+		})
+	}
+
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering || node.Type == bf.Text {
+			return bf.GoToNext
+		}
+		node.Literal = restore(node.Literal)
+		node.LinkData.Destination = restore(node.LinkData.Destination)
+		node.LinkData.Title = restore(node.LinkData.Title)
+		return bf.GoToNext
+	})
+	replaceText(doc, func(text []byte) []*bf.Node {
+		return replaceMatches(text, mathPlaceholderRE, func(m [][]byte) (string, bool) {
+			tex, ok := expr(m[2])
+			if !ok {
+				return "", false
+			}
+			return string(r.renderMath(tex, false)), true
+		})
+	})
+}
+
+// renderMath renders tex with the configured MathRenderer, or as
+// MathJax-compatible markup with the TeX HTML escaped.
+func (r *renderer) renderMath(tex string, display bool) []byte {
+	if r.opts.MathRenderer != nil {
+		if out, ok := r.opts.MathRenderer.RenderMath(tex, display); ok {
+			return out
+		}
+	}
+	if display {
+		return []byte(`<div class="math math-display">\[` + template.HTMLEscapeString(tex) + `\]</div>`)
+	}
+	return []byte(`<span class="math math-inline">\(` + template.HTMLEscapeString(tex) + `\)</span>`)
+}
@@ -0,0 +1,62 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestMath(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			// Contents aren't parsed as Markdown.
+			text: `Euler: $e^{i\pi} + 1 = 0$ and $a_1 * b_2 * c$.`,
+			want: `<p>Euler: <span class="math math-inline">\(e^{i\pi} + 1 = 0\)</span> and <span class="math math-inline">\(a_1 * b_2 * c\)</span>.</p>` + "\n",
+		},
+		{
+			// Prices, escaped dollars and code aren't math.
+			text: "Costs $5 and $6. Escaped \\$x\\$, `$code$`.",
+			want: "<p>Costs $5 and $6. Escaped \\$x\\$, <code>$code$</code>.</p>\n",
+		},
+		{
+			text: "$$\n\\sum_{i=1}^n i < x\n$$\n",
+			want: `<div class="math math-display">\[\sum_{i=1}^n i &lt; x\]</div>`,
+		},
+		{
+			text: "```math\nx_1 < y\n```\n",
+			want: `<div class="math math-display">\[x_1 &lt; y\]</div>`,
+		},
+		{
+			// Placeholders outside text are restored.
+			text: "[$a$](https://example.com/$a$)\n\n    $b$\n",
+			want: `<p><a href="https://example.com/$a$" rel="nofollow"><span class="math math-inline">\(a\)</span></a></p>` + "\n<pre><code>$b$\n</code></pre>",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithMath())); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestMathRenderer(t *testing.T) {
+	m := github_flavored_markdown.MathRendererFunc(func(tex string, display bool) ([]byte, bool) {
+		if tex == "bad" {
+			return nil, false
+		}
+		if display {
+			return []byte("<div>display " + tex + "</div>"), true
+		}
+		return []byte("<span>inline " + tex + "</span>"), true
+	})
+	text := "$x$ $bad$\n\n$$y$$\n"
+	want := `<p><span>inline x</span> <span class="math math-inline">\(bad\)</span></p>` + "\n<div>display y</div>"
+
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithMathRenderer(m))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
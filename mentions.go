@@ -0,0 +1,32 @@
+package github_flavored_markdown
+
+import (
+	"fmt"
+	"regexp"
+	"text/template"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// MentionResolver returns the URL that a mention of the user or team
+// with the given name links to, or false if it's not known.
+type MentionResolver func(name string) (url string, ok bool)
+
+// mentionRE matches @mentions of GitHub-style user names, which consist
+// of alphanumerics and single hyphens, and team names like @org/team.
+// The mention must not follow a word character, so emails don't match.
+var mentionRE = regexp.MustCompile(`(?:^|[^\w@/.` + "`" + `])(@([A-Za-z0-9](?:-?[A-Za-z0-9])*(?:/[A-Za-z0-9][\w-]*)?))\b`)
+
+// mentions links the @mentions in the text of doc that resolve resolves.
+// Text in links is skipped.
+func mentions(doc *bf.Node, resolve MentionResolver) {
+	replaceUnlinkedText(doc, func(text []byte) []*bf.Node {
+		return replaceMatches(text, mentionRE, func(m [][]byte) (string, bool) {
+			url, ok := resolve(string(m[2]))
+			if !ok {
+				return "", false
+			}
+			return fmt.Sprintf(`<a href="%s" class="user-mention">%s</a>`, template.HTMLEscapeString(url), template.HTMLEscapeString(string(m[1]))), true
+		})
+	})
+}
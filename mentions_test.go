@@ -0,0 +1,42 @@
+package github_flavored_markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestMentions(t *testing.T) {
+	resolve := func(name string) (string, bool) {
+		if strings.HasPrefix(name, "ghost") {
+			return "", false
+		}
+		return "https://github.com/" + name, true
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "Thanks @octocat and @my-org/reviewers!",
+			want: `<p>Thanks <a href="https://github.com/octocat" class="user-mention" rel="nofollow">@octocat</a> and <a href="https://github.com/my-org/reviewers" class="user-mention" rel="nofollow">@my-org/reviewers</a>!</p>` + "\n",
+		},
+		{
+			// Unresolved mentions, emails and code are left as is.
+			text: "@ghost, mail me@example.com `@octocat`",
+			want: `<p>@ghost, mail <a href="mailto:me@example.com" rel="nofollow">me@example.com</a> <code>@octocat</code></p>` + "\n",
+		},
+		{
+			// Mentions in links aren't linked again.
+			text: "[@octocat](https://example.com/)",
+			want: `<p><a href="https://example.com/" rel="nofollow">@octocat</a></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithMentions(resolve))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
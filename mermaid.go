@@ -0,0 +1,29 @@
+package github_flavored_markdown
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// MermaidRenderer renders the source of a Mermaid diagram to SVG on the
+// server. It reports false if it can't, in which case the source is emitted
+// for rendering in the browser.
+type MermaidRenderer func(src []byte) (svg []byte, ok bool)
+
+// mermaid writes a mermaid code block, as an image of the SVG that
+// opts.MermaidRenderer renders, or as <pre class="mermaid"> with the
+// source for client-side Mermaid. The SVG is embedded as a data URI image,
+// so that the sanitizer keeps it, and scripts in it don't run.
+func (r *renderer) mermaid(w io.Writer, src []byte) {
+	if r.opts.MermaidRenderer != nil {
+		if svg, ok := r.opts.MermaidRenderer(src); ok {
+			io.WriteString(w, `<img class="mermaid" alt="Mermaid diagram" src="data:image/svg+xml;base64,`)
+			io.WriteString(w, base64.StdEncoding.EncodeToString(svg))
+			io.WriteString(w, `">`)
+			return
+		}
+	}
+	io.WriteString(w, `<pre class="mermaid">`)
+	attrEscape(w, src)
+	io.WriteString(w, `</pre>`)
+}
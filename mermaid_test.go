@@ -0,0 +1,22 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestMermaid(t *testing.T) {
+	text := "```mermaid\ngraph TD;\n  A-->B;\n```\n"
+
+	want := "<pre class=\"mermaid\">graph TD;\n  A--&gt;B;\n</pre>"
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithMermaid())); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	svg := func(src []byte) ([]byte, bool) { return []byte("<svg></svg>"), true }
+	want = `<img class="mermaid" alt="Mermaid diagram" src="data:image/svg+xml;base64,PHN2Zz48L3N2Zz4=">`
+	if got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithMermaidRenderer(svg))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
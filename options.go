@@ -0,0 +1,937 @@
+package github_flavored_markdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// Options controls optional rendering behavior. The zero value
+// renders the same output as Markdown.
+type Options struct {
+	// StripTrackingParams removes known tracking query parameters
+	// from link and image URLs. The visible link text is left as is.
+	StripTrackingParams bool
+
+	// TrackingParams lists the query parameters removed when StripTrackingParams is set.
+	// A trailing "*" matches any parameter with that prefix.
+	// If nil, DefaultTrackingParams is used.
+	TrackingParams []string
+
+	// CopyableHeadingAnchors renders heading anchors as buttons that hold the
+	// anchor in a data-anchor attribute, rather than as links, so that a page can
+	// copy the permalink to the clipboard on click. No JavaScript is included in
+	// the output; a page can hook the click with something like:
+	//
+	// 	document.addEventListener("click", function(e) {
+	// 		var b = e.target.closest("button.copy-anchor");
+	// 		if (b) navigator.clipboard.writeText(location.href.split("#")[0] + b.dataset.anchor);
+	// 	});
+	//
+	// The default policy only allows these buttons if CopyableHeadingAnchors
+	// is set.
+	CopyableHeadingAnchors bool
+
+	// RandSeed seeds the randomness of generated markup, so that output is
+	// reproducible for the same input and seed. Nothing is randomized yet:
+	// generated element ids, such as those of headings and footnotes, are
+	// derived from the text, so output is already reproducible. RandSeed
+	// is for features that will need randomness, such as nonces.
+	RandSeed int64
+
+	// Spoilers renders text between "||" markers, as in ||spoiler||,
+	// inside a <span class="spoiler"> element that CSS can hide until hovered.
+	// Markers are not recognized in code spans or table cells.
+	Spoilers bool
+
+	// CodeLangBadge renders a <span class="lang-badge"> with the language name
+	// at the start of each fenced code block that specifies a language.
+	CodeLangBadge bool
+
+	// Footnotes renders footnote references, as in "text[^1]", as superscript
+	// links to a footnotes section appended to the output, which lists the
+	// definitions, lines like "[^1]: Note.", in order of first reference.
+	// References to undefined footnotes are left as is. The markup matches
+	// GitHub's, with element ids prefixed with "user-content-".
+	//
+	// Footnotes are handled before and after parsing, independently of
+	// blackfriday's Footnotes extension.
+	Footnotes bool
+
+	// DefaultCodeLang is the language used to highlight fenced code blocks
+	// that don't specify one. An explicit language takes precedence.
+	DefaultCodeLang string
+
+	// RichTableCells renders table cells that contain <br> tags as block content:
+	// each <br>-separated part becomes a paragraph, and parts starting with
+	// list markers, as in "- a<br>- b", become a list. This is not standard
+	// GitHub Flavored Markdown, which only allows inline content in cells.
+	RichTableCells bool
+
+	// AMP renders output that is valid inside an AMP HTML document.
+	// Images are rendered as <amp-img> elements with a responsive layout,
+	// using a 16:9 aspect ratio unless a raw <img> specifies its width and height.
+	// Elements AMP forbids in content, such as <iframe>, <input> (so task list
+	// checkboxes), <video> and <audio>, are removed, as are style and event
+	// handler attributes.
+	AMP bool
+
+	// DirAuto adds a dir="auto" attribute to paragraphs, headings, list items,
+	// blockquotes and table cells, so that each takes its text direction from
+	// its content. This helps documents that mix right-to-left and
+	// left-to-right text.
+	DirAuto bool
+
+	// Policy is the policy used to sanitize the output. If nil, the default
+	// policy is used. NewPolicy returns a copy of the default policy that can be
+	// extended.
+	Policy *bluemonday.Policy
+
+	// SkipSanitization returns the rendered output without sanitizing it.
+	// Raw HTML in the input, including <script> elements, event handler
+	// attributes and javascript: URLs, is passed through unchanged.
+	// It must never be set when rendering untrusted input.
+	SkipSanitization bool
+
+	// MaxInputSize is the maximum size of input in bytes. MarkdownReader,
+	// MarkdownTo and MarkdownContext return an *InputTooLargeError for
+	// larger input; the other functions render input cut to the last full
+	// line within the limit. Zero means DefaultMaxInputSize, and a negative
+	// size means no limit.
+	MaxInputSize int64
+
+	// MaxNestingDepth is the maximum nesting depth of block quotes, lists
+	// and inline elements such as emphasis. Deeper markers and elements are
+	// rendered as literal text. Zero means DefaultMaxNestingDepth, and a
+	// negative depth means no limit. Content nested deeper than 15 levels
+	// is dropped by the parser regardless.
+	MaxNestingDepth int
+
+	// MaxNodes is the maximum number of nodes in the parsed document.
+	// The nodes after the limit are rendered as literal text. Zero means
+	// DefaultMaxNodes, and a negative number means no limit.
+	MaxNodes int
+
+	// MaxHighlightSize and MaxHighlightLines are the size in bytes and
+	// the number of lines of the largest code blocks that are highlighted.
+	// Larger code blocks are only escaped. Zero means
+	// DefaultMaxHighlightSize and DefaultMaxHighlightLines, and a negative
+	// value means no limit.
+	MaxHighlightSize  int
+	MaxHighlightLines int
+
+	// MaxOutputSize is the maximum size in bytes of the rendered HTML,
+	// before sanitization. When the output grows larger, rendering stops.
+	// MarkdownReader, MarkdownTo and MarkdownContext then return an
+	// *OutputTooLargeError; the other functions return the input as
+	// escaped plain text instead. Zero means no limit.
+	MaxOutputSize int64
+
+	// Timeout is the maximum duration of parsing and rendering. When it
+	// elapses, rendering stops and the elements open at that point are
+	// closed. The output rendered so far is returned, and the functions
+	// that return an error return ErrTimeout. Zero means no timeout.
+	Timeout time.Duration
+
+	// Cache, if set, stores rendered output by a hash of the input and
+	// these options, and returns stored output for repeated renders. Output
+	// is not cached when these options hold functions, such as a
+	// HighlighterFunc, Transformers or References.Resolve, since those
+	// can't be compared. Values that these options point to are compared by
+	// value, except for Policy, which is compared by identity and must not
+	// be modified while the cache is in use. Partial output, such as after
+	// a timeout, is not cached.
+	Cache Cache
+
+	// Highlighter, if not nil, is used to highlight code blocks before
+	// the built-in highlighters.
+	Highlighter Highlighter
+
+	// CodeClasses, if not nil, renames the classes of the code highlighted
+	// by the built-in highlighters, such as to those of highlight.js with
+	// HLJSClasses, so that other stylesheets apply to it.
+	CodeClasses *ClassScheme
+
+	// LineNumbers prefixes each line of code blocks with its line number,
+	// in a <span class="ln"> element.
+	LineNumbers bool
+
+	// LanguageAliases maps alternative names of code block languages, such as
+	// "golang", to the name used for highlighting and for the highlight-<lang>
+	// class. Keys and names must be lowercase, as languages are matched
+	// case-insensitively. WithLanguageAliases lowercases them.
+	// Entries take precedence over DefaultLanguageAliases.
+	LanguageAliases map[string]string
+
+	// Emoji replaces emoji shortcodes like :smile: with the emoji, like GitHub.
+	// GitHub's custom emoji, such as :shipit:, are rendered as images.
+	Emoji bool
+
+	// CustomEmoji maps additional emoji shortcodes, without colons, to image
+	// URLs. They are rendered as <img class="emoji"> elements when Emoji is set,
+	// and take precedence over the standard emoji.
+	CustomEmoji map[string]string
+
+	// Mentions, if set, resolves @mentions of users and teams, such as
+	// @octocat or @org/team, to URLs they are linked to. Mentions that
+	// don't resolve are left as plain text.
+	Mentions MentionResolver
+
+	// References, if set, links references to issues and pull requests,
+	// such as #123 and owner/repo#456, and optionally to commits.
+	References *References
+
+	// DefinitionLists renders definition lists, a term on one line followed
+	// by lines like ": Definition.", as <dl> elements.
+	DefinitionLists bool
+
+	// Math renders TeX math, inline as in "$x^2$", and displayed as in lines
+	// of "$$" around the TeX or in "math" code blocks. By default, the TeX is
+	// wrapped in MathJax-compatible markup, to be typeset in the browser.
+	Math bool
+
+	// MathRenderer, if set, renders math instead, for example with KaTeX.
+	// Its output is sanitized like the rest, so a policy that allows it
+	// may be needed.
+	MathRenderer MathRenderer
+
+	// Mermaid renders mermaid code blocks as <pre class="mermaid"> elements
+	// with the diagram source, for Mermaid to render in the browser.
+	Mermaid bool
+
+	// MermaidRenderer, if set, renders mermaid diagrams to SVG on the server
+	// instead. The SVG is embedded as an <img class="mermaid"> data URI.
+	MermaidRenderer MermaidRenderer
+
+	// Fences maps code block languages to renderers that render them as
+	// something other than code, like RichFence. Languages are matched after
+	// resolving aliases, so keys are lowercase canonical names.
+	Fences map[string]FenceRenderer
+
+	// FrontMatter skips YAML front matter between "---" lines, or TOML front
+	// matter between "+++" lines, at the start of the document. Use
+	// MarkdownMetadata to get it.
+	FrontMatter bool
+
+	// TOC prepends a table of contents to the output, as a <nav class="toc">
+	// element with nested lists of links to the headings. See also TOC.
+	TOC bool
+
+	// TOCMarker, if not empty, is replaced with a table of contents like
+	// the one TOC prepends wherever it makes up a paragraph of its own,
+	// for example DefaultTOCMarker or "<!-- toc -->".
+	TOCMarker string
+
+	// GitHubAnchors names heading anchors exactly like GitHub does, so that
+	// the anchors of a document match those on github.com. The names have
+	// a "user-content-" prefix that, like on GitHub, the anchor links omit.
+	GitHubAnchors bool
+
+	// HeadingIDs puts the anchor name in the id attribute of the heading,
+	// as in <h2 id="name">, rather than the name attribute of its anchor
+	// link. This is what CSS :target selectors expect.
+	HeadingIDs bool
+
+	// HeadingLevelOffset is added to the level of each heading, so that
+	// with an offset of 1, "# Title" renders as <h2>. Levels are clamped
+	// to the range of h1 to h6.
+	HeadingLevelOffset int
+
+	// NoHeadingAnchors renders headings without anchor links, as in
+	// <h2>Title</h2>, for output such as email and feeds. Headings still
+	// get ids if HeadingIDs is set.
+	NoHeadingAnchors bool
+
+	// Slug, if set, returns the anchor name for a heading with the given
+	// plain text title, instead of the built-in rules. Repeated names are
+	// still numbered.
+	Slug func(title string) string
+
+	// Transformers are called, in order, with the root of each parsed
+	// document after the built-in transforms, such as autolinks and emoji,
+	// and before rendering. They can modify the document.
+	Transformers []func(doc *Node)
+
+	// Mode is the GitHub Markdown API mode to render like.
+	Mode Mode
+
+	// Engine is the Markdown engine that renders text. See Goldmark for
+	// the options that it supports.
+	Engine Engine
+
+	// Smartypants converts straight quotes to curly quotes, "--" and "---"
+	// to en and em dashes, "..." to ellipses, and 1/2, 1/4 and 3/4 to
+	// fraction characters. Code is not changed.
+	Smartypants bool
+
+	// SmartypantsFractions converts any fraction, such as 3/8, to
+	// superscript and subscript numbers, if Smartypants is set.
+	SmartypantsFractions bool
+
+	// SmartypantsAngledQuotes converts double quotes to angled quotes,
+	// as in «this», if Smartypants is set.
+	SmartypantsAngledQuotes bool
+
+	// BaseURL, if set, is the URL that relative link and image URLs are
+	// resolved against, such as "https://github.com/o/r/blob/main/" for
+	// a repository's README. Links to fragments, such as "#usage", and
+	// URLs in raw HTML are not changed.
+	BaseURL string
+
+	// ImageProxy, if set, rewrites the URLs of external images to go
+	// through an image proxy, like GitHub does with camo.
+	ImageProxy *ImageProxy
+
+	// LinkRewriter, if set, is called with the destination of each link
+	// and image, after the other options are applied, and returns the URL
+	// to use instead. Links and images in raw HTML are not rewritten.
+	LinkRewriter func(dest string, isImage bool) string
+
+	// ExternalLinksNewTab opens links to other sites in a new tab, with
+	// target="_blank" and rel="noopener noreferrer nofollow". Links to
+	// InternalHosts and relative links are not changed.
+	ExternalLinksNewTab bool
+
+	// InternalHosts lists the hosts that are not external for
+	// ExternalLinksNewTab and LinkRel, such as "example.com".
+	InternalHosts []string
+
+	// LinkRel lists the rel tokens of links to other sites, such as
+	// "nofollow", "ugc", "sponsored" and "noopener". If nil, "nofollow"
+	// is used.
+	//
+	// The default policy adds rel="nofollow" to every link. If LinkRel or
+	// InternalLinkRel is set, it leaves rel to the renderer instead, and
+	// links in raw HTML keep the rel tokens they were written with. It
+	// still adds noreferrer to links with a host, including those to
+	// InternalHosts, and noopener to links with target="_blank".
+	LinkRel []string
+
+	// InternalLinkRel lists the rel tokens of heading anchors, relative
+	// links and links to InternalHosts. If nil, "nofollow" is used.
+	InternalLinkRel []string
+
+	// URLSchemes lists URL schemes to allow in links and images, such as
+	// "irc", "magnet" or "notes", in addition to those the default policy
+	// allows: http, https and mailto. The javascript, vbscript and data
+	// schemes can't be allowed this way. URLSchemes is ignored if Policy
+	// is set.
+	URLSchemes []string
+
+	// IframeOrigins lists the origins that <iframe> elements in raw HTML
+	// may embed, such as "https://www.youtube.com". Iframes from other
+	// origins are removed. Embedded iframes are sandboxed: tokens of their
+	// sandbox attribute other than IframeSandbox are removed, and iframes
+	// without one are given IframeSandbox. Their allow attribute is
+	// limited to features such as autoplay, fullscreen and encrypted-media.
+	// IframeOrigins is ignored if Policy is set.
+	IframeOrigins []string
+
+	// IframeSandbox lists the sandbox tokens that iframes embedded with
+	// IframeOrigins may have. If nil, DefaultIframeSandbox is used.
+	IframeSandbox []string
+
+	// MaxDataURISize is the maximum decoded size in bytes of data URI
+	// images, such as ![](data:image/png;base64,...). The sources of larger
+	// images are removed by the sanitizer. Zero means no limit.
+	// MaxDataURISize is ignored if Policy is set.
+	MaxDataURISize int
+
+	// DataURITypes lists the MIME types allowed in data URI images.
+	// If nil, DefaultDataURITypes is used. DataURITypes is ignored if
+	// Policy is set.
+	DataURITypes []string
+
+	// SVG is how inline <svg> elements in raw HTML are rendered.
+	// SVGSanitize is ignored if Policy is set.
+	SVG SVGMode
+
+	// RasterizeSVG returns the source of an image to replace the inline SVG
+	// svg with, such as a data:image/png URI, if SVG is SVGRasterize. The
+	// SVG is removed if it returns an error.
+	RasterizeSVG func(svg []byte) (src string, err error)
+
+	// TagFilter escapes the tags that the GFM tagfilter extension disallows
+	// in raw HTML, such as <script>, <style> and <iframe>, like GitHub does,
+	// so that they render as text rather than being removed by the
+	// sanitizer.
+	TagFilter bool
+}
+
+// Engine is a Markdown engine, which parses text and renders it to the HTML
+// that the options apply to.
+type Engine int
+
+const (
+	// Blackfriday is the default engine, based on blackfriday, which
+	// supports all options.
+	Blackfriday Engine = iota
+
+	// Goldmark is an engine based on goldmark, which follows the GitHub
+	// Flavored Markdown spec more closely than Blackfriday. It's only
+	// available when the package is built with the goldmark build tag;
+	// otherwise Blackfriday is used instead.
+	//
+	// Goldmark renders the syntax of the spec, including tables, task
+	// lists, strikethrough and autolinks, with the same heading anchors,
+	// code highlighting and sanitization as Blackfriday. Of the options,
+	// it supports those that apply to these, such as HeadingIDs,
+	// LineNumbers and CodeClasses, as well as Mode, Smartypants,
+	// FrontMatter, the link rewriting options and the limits on input and
+	// output size. The options that extend the syntax, such as Footnotes,
+	// Emoji, Spoilers and Mentions, and Transformers, which work on
+	// the documents of Blackfriday, are ignored, and Timeout only
+	// stops code highlighting. The functions that return information
+	// about documents, such as TOC and Links, and Parse always use
+	// Blackfriday.
+	Goldmark
+)
+
+// Mode is a rendering mode of the GitHub Markdown API.
+type Mode int
+
+const (
+	// DocumentMode renders text like GitHub renders Markdown files, in
+	// the "markdown" mode of the API.
+	DocumentMode Mode = iota
+
+	// CommentMode renders text like GitHub renders issues and comments,
+	// in the "gfm" mode of the API, where each newline in a paragraph is
+	// a line break.
+	CommentMode
+)
+
+// SVGMode is how inline <svg> elements in raw HTML are rendered.
+// SVG images linked with ![](image.svg) are always allowed, since browsers
+// don't run scripts in images.
+type SVGMode int
+
+const (
+	// SVGRemove removes inline SVG, as the default policy does.
+	SVGRemove SVGMode = iota
+
+	// SVGSanitize keeps inline SVG, with scripts, styles, event handlers,
+	// <foreignObject> elements and external references removed.
+	SVGSanitize
+
+	// SVGRasterize replaces inline SVG with an image whose source is
+	// returned by Options.RasterizeSVG.
+	SVGRasterize
+)
+
+// Option configures rendering in Markdown.
+type Option func(*Options)
+
+func newOptions(opts []Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// sanitizer returns the policy used to sanitize output rendered with opts,
+// or nil if the output isn't sanitized.
+func (opts Options) sanitizer() *bluemonday.Policy {
+	switch {
+	case opts.SkipSanitization:
+		return nil
+	case opts.Policy != nil:
+		return opts.Policy
+	case len(opts.URLSchemes) > 0 || len(opts.IframeOrigins) > 0 ||
+		opts.MaxDataURISize > 0 || opts.DataURITypes != nil || opts.SVG == SVGSanitize ||
+		opts.CopyableHeadingAnchors:
+		return customPolicy(opts)
+	case opts.AMP:
+		return ampPolicy
+	case opts.LinkRel != nil || opts.InternalLinkRel != nil:
+		return linkRelPolicy
+	default:
+		return policy
+	}
+}
+
+// customPolicies caches the policies returned by customPolicy.
+var customPolicies sync.Map // map[string]*bluemonday.Policy
+
+// unsafeSchemes are the URL schemes that Options.URLSchemes can't allow.
+var unsafeSchemes = map[string]bool{"javascript": true, "vbscript": true, "data": true}
+
+// customPolicy returns the default policy for opts, extended to allow the
+// URL schemes in opts.URLSchemes, the iframes from opts.IframeOrigins,
+// inline SVG for SVGSanitize and the buttons of CopyableHeadingAnchors,
+// with data URI images limited by opts.MaxDataURISize and
+// opts.DataURITypes.
+func customPolicy(opts Options) *bluemonday.Policy {
+	var schemes []string
+	for _, s := range opts.URLSchemes {
+		s = strings.ToLower(strings.TrimSuffix(s, ":"))
+		if !unsafeSchemes[s] {
+			schemes = append(schemes, s)
+		}
+	}
+	sort.Strings(schemes)
+	origins := iframeOrigins(opts)
+	sandbox := opts.iframeSandbox()
+	dataTypes := opts.dataURITypes()
+	linkRel := opts.LinkRel != nil || opts.InternalLinkRel != nil
+	svg := opts.SVG == SVGSanitize
+	key := fmt.Sprint(opts.AMP, linkRel, schemes, origins, sandbox, opts.MaxDataURISize, dataTypes, svg, opts.CopyableHeadingAnchors)
+	if p, ok := customPolicies.Load(key); ok {
+		return p.(*bluemonday.Policy)
+	}
+
+	var p *bluemonday.Policy
+	if opts.AMP {
+		p = newAMPPolicy()
+	} else {
+		p = newPolicy()
+		if linkRel {
+			leaveLinkRel(p)
+		}
+		allowDataURIImages(p, opts.MaxDataURISize, dataTypes)
+	}
+	if len(schemes) > 0 {
+		p.AllowURLSchemes(schemes...)
+	}
+	if len(origins) > 0 {
+		allowIframes(p, origins, sandbox)
+	}
+	if svg {
+		allowSVG(p)
+	}
+	if opts.CopyableHeadingAnchors {
+		allowCopyAnchors(p)
+	}
+	actual, _ := customPolicies.LoadOrStore(key, p)
+	return actual.(*bluemonday.Policy)
+}
+
+// maxInputSize returns the maximum input size for opts, or 0 for no limit.
+func (opts Options) maxInputSize() int64 {
+	switch {
+	case opts.MaxInputSize < 0:
+		return 0
+	case opts.MaxInputSize == 0:
+		return DefaultMaxInputSize
+	default:
+		return opts.MaxInputSize
+	}
+}
+
+// htmlFlags returns the blackfriday HTML renderer flags to render with.
+func (opts Options) htmlFlags() bf.HTMLFlags {
+	flags := bf.HTMLFlagsNone
+	if opts.Smartypants {
+		flags |= bf.Smartypants | bf.SmartypantsDashes | bf.SmartypantsLatexDashes
+		if opts.SmartypantsFractions {
+			flags |= bf.SmartypantsFractions
+		}
+		if opts.SmartypantsAngledQuotes {
+			flags |= bf.SmartypantsAngledQuotes
+		}
+	}
+	return flags
+}
+
+// extensions returns the blackfriday extensions to parse with.
+func (opts Options) extensions() bf.Extensions {
+	ext := extensions
+	if opts.DefinitionLists {
+		ext |= bf.DefinitionLists
+	}
+	if opts.Mode == CommentMode {
+		ext |= bf.HardLineBreak
+	}
+	return ext
+}
+
+// WithOptions sets all options to o, replacing those set by earlier options.
+func WithOptions(o Options) Option {
+	return func(opts *Options) { *opts = o }
+}
+
+// WithStripTrackingParams strips tracking query parameters from link and
+// image URLs. If params are given, they replace DefaultTrackingParams.
+// See Options.StripTrackingParams.
+func WithStripTrackingParams(params ...string) Option {
+	return func(opts *Options) {
+		opts.StripTrackingParams = true
+		if len(params) > 0 {
+			opts.TrackingParams = params
+		}
+	}
+}
+
+// WithCopyableHeadingAnchors renders heading anchors as copy buttons.
+// See Options.CopyableHeadingAnchors.
+func WithCopyableHeadingAnchors() Option {
+	return func(opts *Options) { opts.CopyableHeadingAnchors = true }
+}
+
+// WithRandSeed seeds randomness used while rendering. See Options.RandSeed.
+func WithRandSeed(seed int64) Option {
+	return func(opts *Options) { opts.RandSeed = seed }
+}
+
+// WithSpoilers enables ||spoiler|| syntax. See Options.Spoilers.
+func WithSpoilers() Option {
+	return func(opts *Options) { opts.Spoilers = true }
+}
+
+// WithCodeLangBadge renders language badges on code blocks.
+// See Options.CodeLangBadge.
+func WithCodeLangBadge() Option {
+	return func(opts *Options) { opts.CodeLangBadge = true }
+}
+
+// WithFootnotes enables footnotes. See Options.Footnotes.
+func WithFootnotes() Option {
+	return func(opts *Options) { opts.Footnotes = true }
+}
+
+// WithDefaultCodeLang sets the language of unlabeled fenced code blocks.
+// See Options.DefaultCodeLang.
+func WithDefaultCodeLang(lang string) Option {
+	return func(opts *Options) { opts.DefaultCodeLang = lang }
+}
+
+// WithRichTableCells enables block content in table cells.
+// See Options.RichTableCells.
+func WithRichTableCells() Option {
+	return func(opts *Options) { opts.RichTableCells = true }
+}
+
+// WithAMP renders output for AMP HTML documents. See Options.AMP.
+func WithAMP() Option {
+	return func(opts *Options) { opts.AMP = true }
+}
+
+// WithDirAuto adds dir="auto" to block elements. See Options.DirAuto.
+func WithDirAuto() Option {
+	return func(opts *Options) { opts.DirAuto = true }
+}
+
+// WithPolicy sets the policy used to sanitize the output. See Options.Policy.
+func WithPolicy(p *bluemonday.Policy) Option {
+	return func(opts *Options) { opts.Policy = p }
+}
+
+// Unsafe skips sanitization of the output. It must never be used when
+// rendering untrusted input. See Options.SkipSanitization.
+func Unsafe() Option {
+	return func(opts *Options) { opts.SkipSanitization = true }
+}
+
+// WithMaxInputSize limits the size of the input. See Options.MaxInputSize.
+func WithMaxInputSize(n int64) Option {
+	return func(opts *Options) { opts.MaxInputSize = n }
+}
+
+// WithHighlighter sets a highlighter used before the built-in highlighters.
+// See Options.Highlighter.
+func WithHighlighter(h Highlighter) Option {
+	return func(opts *Options) { opts.Highlighter = h }
+}
+
+// WithCodeClasses renames the classes of highlighted code according to s.
+// See Options.CodeClasses.
+func WithCodeClasses(s *ClassScheme) Option {
+	return func(opts *Options) { opts.CodeClasses = s }
+}
+
+// WithLineNumbers numbers the lines of code blocks. See Options.LineNumbers.
+func WithLineNumbers() Option {
+	return func(opts *Options) { opts.LineNumbers = true }
+}
+
+// WithLanguageAliases adds code block language aliases.
+// See Options.LanguageAliases.
+func WithLanguageAliases(aliases map[string]string) Option {
+	return func(opts *Options) {
+		merged := make(map[string]string, len(opts.LanguageAliases)+len(aliases))
+		for alias, name := range opts.LanguageAliases {
+			merged[alias] = name
+		}
+		for alias, name := range aliases {
+			merged[normalizeLang(alias)] = normalizeLang(name)
+		}
+		opts.LanguageAliases = merged
+	}
+}
+
+// WithEmoji replaces emoji shortcodes with emoji. See Options.Emoji.
+func WithEmoji() Option {
+	return func(opts *Options) { opts.Emoji = true }
+}
+
+// WithCustomEmoji replaces emoji shortcodes with emoji, including the given
+// custom emoji images. See Options.CustomEmoji.
+func WithCustomEmoji(emoji map[string]string) Option {
+	return func(opts *Options) {
+		opts.Emoji = true
+		merged := make(map[string]string, len(opts.CustomEmoji)+len(emoji))
+		for name, src := range opts.CustomEmoji {
+			merged[name] = src
+		}
+		for name, src := range emoji {
+			merged[name] = src
+		}
+		opts.CustomEmoji = merged
+	}
+}
+
+// WithMentions links @mentions that resolve resolves. See Options.Mentions.
+func WithMentions(resolve MentionResolver) Option {
+	return func(opts *Options) { opts.Mentions = resolve }
+}
+
+// WithReferences links references to issues, pull requests and commits.
+// See Options.References.
+func WithReferences(refs References) Option {
+	return func(opts *Options) { opts.References = &refs }
+}
+
+// WithDefinitionLists enables definition lists. See Options.DefinitionLists.
+func WithDefinitionLists() Option {
+	return func(opts *Options) { opts.DefinitionLists = true }
+}
+
+// WithMath enables math. See Options.Math.
+func WithMath() Option {
+	return func(opts *Options) { opts.Math = true }
+}
+
+// WithMathRenderer enables math, rendered with m. See Options.MathRenderer.
+func WithMathRenderer(m MathRenderer) Option {
+	return func(opts *Options) {
+		opts.Math = true
+		opts.MathRenderer = m
+	}
+}
+
+// WithMermaid enables Mermaid diagrams. See Options.Mermaid.
+func WithMermaid() Option {
+	return func(opts *Options) { opts.Mermaid = true }
+}
+
+// WithMermaidRenderer enables Mermaid diagrams, rendered on the server with m.
+// See Options.MermaidRenderer.
+func WithMermaidRenderer(m MermaidRenderer) Option {
+	return func(opts *Options) {
+		opts.Mermaid = true
+		opts.MermaidRenderer = m
+	}
+}
+
+// WithFence renders code blocks in language lang with f. See Options.Fences.
+func WithFence(lang string, f FenceRenderer) Option {
+	return func(opts *Options) {
+		fences := make(map[string]FenceRenderer, len(opts.Fences)+1)
+		for l, f := range opts.Fences {
+			fences[l] = f
+		}
+		fences[normalizeLang(lang)] = f
+		opts.Fences = fences
+	}
+}
+
+// WithRichFences renders code blocks in RichFenceLanguages with RichFence.
+func WithRichFences() Option {
+	return func(opts *Options) {
+		for _, lang := range RichFenceLanguages {
+			WithFence(lang, RichFence)(opts)
+		}
+	}
+}
+
+// WithFrontMatter skips front matter. See Options.FrontMatter.
+func WithFrontMatter() Option {
+	return func(opts *Options) { opts.FrontMatter = true }
+}
+
+// WithTOC prepends a table of contents. See Options.TOC.
+func WithTOC() Option {
+	return func(opts *Options) { opts.TOC = true }
+}
+
+// WithTOCMarker replaces marker with a table of contents. See Options.TOCMarker.
+func WithTOCMarker(marker string) Option {
+	return func(opts *Options) { opts.TOCMarker = marker }
+}
+
+// WithGitHubAnchors names heading anchors like GitHub. See Options.GitHubAnchors.
+func WithGitHubAnchors() Option {
+	return func(opts *Options) { opts.GitHubAnchors = true }
+}
+
+// WithHeadingIDs puts anchor names on headings. See Options.HeadingIDs.
+func WithHeadingIDs() Option {
+	return func(opts *Options) { opts.HeadingIDs = true }
+}
+
+// HeadingLevelOffset adds n to the level of each heading. See Options.HeadingLevelOffset.
+func HeadingLevelOffset(n int) Option {
+	return func(opts *Options) { opts.HeadingLevelOffset = n }
+}
+
+// WithoutHeadingAnchors renders headings without anchor links. See Options.NoHeadingAnchors.
+func WithoutHeadingAnchors() Option {
+	return func(opts *Options) { opts.NoHeadingAnchors = true }
+}
+
+// WithSlug names heading anchors with slug. See Options.Slug.
+func WithSlug(slug func(title string) string) Option {
+	return func(opts *Options) { opts.Slug = slug }
+}
+
+// WithTransformer adds a transformer for parsed documents. See Options.Transformers.
+func WithTransformer(transform func(doc *Node)) Option {
+	return func(opts *Options) {
+		transformers := make([]func(doc *Node), 0, len(opts.Transformers)+1)
+		transformers = append(transformers, opts.Transformers...)
+		opts.Transformers = append(transformers, transform)
+	}
+}
+
+// WithMode renders text like the GitHub Markdown API mode m. See Options.Mode.
+func WithMode(m Mode) Option {
+	return func(opts *Options) { opts.Mode = m }
+}
+
+// WithEngine renders text with the Markdown engine e. See Options.Engine.
+func WithEngine(e Engine) Option {
+	return func(opts *Options) { opts.Engine = e }
+}
+
+// WithSmartypants enables smart punctuation. Fractions and angled quotes
+// can be enabled too with Options.SmartypantsFractions and
+// Options.SmartypantsAngledQuotes. See Options.Smartypants.
+func WithSmartypants() Option {
+	return func(opts *Options) { opts.Smartypants = true }
+}
+
+// WithBaseURL resolves relative URLs against base. See Options.BaseURL.
+func WithBaseURL(base string) Option {
+	return func(opts *Options) { opts.BaseURL = base }
+}
+
+// WithLinkRewriter rewrites link and image URLs with f. See Options.LinkRewriter.
+func WithLinkRewriter(f func(dest string, isImage bool) string) Option {
+	return func(opts *Options) { opts.LinkRewriter = f }
+}
+
+// WithImageProxy routes external images through p. See Options.ImageProxy.
+func WithImageProxy(p ImageProxy) Option {
+	return func(opts *Options) { opts.ImageProxy = &p }
+}
+
+// WithExternalLinksNewTab opens links to hosts other than internalHosts in
+// a new tab. See Options.ExternalLinksNewTab.
+func WithExternalLinksNewTab(internalHosts ...string) Option {
+	return func(opts *Options) {
+		opts.ExternalLinksNewTab = true
+		opts.InternalHosts = internalHosts
+	}
+}
+
+// WithLinkRel sets the rel tokens of links to other sites. See Options.LinkRel.
+func WithLinkRel(tokens ...string) Option {
+	return func(opts *Options) { opts.LinkRel = append([]string{}, tokens...) }
+}
+
+// WithInternalLinkRel sets the rel tokens of heading anchors and links
+// within the site. See Options.InternalLinkRel.
+func WithInternalLinkRel(tokens ...string) Option {
+	return func(opts *Options) { opts.InternalLinkRel = append([]string{}, tokens...) }
+}
+
+// WithURLSchemes allows links and images with the given URL schemes.
+// See Options.URLSchemes.
+func WithURLSchemes(schemes ...string) Option {
+	return func(opts *Options) {
+		urlSchemes := make([]string, 0, len(opts.URLSchemes)+len(schemes))
+		urlSchemes = append(urlSchemes, opts.URLSchemes...)
+		opts.URLSchemes = append(urlSchemes, schemes...)
+	}
+}
+
+// WithTagFilter escapes disallowed raw HTML tags. See Options.TagFilter.
+func WithTagFilter() Option {
+	return func(opts *Options) { opts.TagFilter = true }
+}
+
+// WithIframeOrigins allows iframes that embed the given origins.
+// See Options.IframeOrigins.
+func WithIframeOrigins(origins ...string) Option {
+	return func(opts *Options) {
+		iframeOrigins := make([]string, 0, len(opts.IframeOrigins)+len(origins))
+		iframeOrigins = append(iframeOrigins, opts.IframeOrigins...)
+		opts.IframeOrigins = append(iframeOrigins, origins...)
+	}
+}
+
+// WithDataURILimits limits the decoded size of data URI images to maxSize
+// bytes, and their MIME types to types, if any are given.
+// See Options.MaxDataURISize and Options.DataURITypes.
+func WithDataURILimits(maxSize int, types ...string) Option {
+	return func(opts *Options) {
+		opts.MaxDataURISize = maxSize
+		if len(types) > 0 {
+			opts.DataURITypes = types
+		}
+	}
+}
+
+// WithSVGSanitize keeps inline SVG, sanitized. See SVGSanitize.
+func WithSVGSanitize() Option {
+	return func(opts *Options) { opts.SVG = SVGSanitize }
+}
+
+// WithSVGRasterize replaces inline SVG with the image rasterize returns.
+// See SVGRasterize.
+func WithSVGRasterize(rasterize func(svg []byte) (src string, err error)) Option {
+	return func(opts *Options) {
+		opts.SVG = SVGRasterize
+		opts.RasterizeSVG = rasterize
+	}
+}
+
+// WithNestingLimits limits the nesting depth and the number of nodes of
+// documents. See Options.MaxNestingDepth and Options.MaxNodes.
+func WithNestingLimits(depth, nodes int) Option {
+	return func(opts *Options) {
+		opts.MaxNestingDepth = depth
+		opts.MaxNodes = nodes
+	}
+}
+
+// WithHighlightLimits limits the size and the number of lines of the code
+// blocks that are highlighted. See Options.MaxHighlightSize and
+// Options.MaxHighlightLines.
+func WithHighlightLimits(size, lines int) Option {
+	return func(opts *Options) {
+		opts.MaxHighlightSize = size
+		opts.MaxHighlightLines = lines
+	}
+}
+
+// WithMaxOutputSize limits the size of the output. See Options.MaxOutputSize.
+func WithMaxOutputSize(n int64) Option {
+	return func(opts *Options) { opts.MaxOutputSize = n }
+}
+
+// WithTimeout limits the duration of rendering to d. See Options.Timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(opts *Options) { opts.Timeout = d }
+}
+
+// WithCache stores rendered output in c. See Options.Cache.
+func WithCache(c Cache) Option {
+	return func(opts *Options) { opts.Cache = c }
+}
@@ -0,0 +1,77 @@
+package github_flavored_markdown_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestOptions(t *testing.T) {
+	text := []byte("Text[^1] ||secret||.\n\n[^1]: A note.\n")
+	want := string(github_flavored_markdown.MarkdownOptions(text, github_flavored_markdown.Options{Footnotes: true, Spoilers: true}))
+
+	if got := string(github_flavored_markdown.Markdown(text, github_flavored_markdown.WithFootnotes(), github_flavored_markdown.WithSpoilers())); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+	if got := string(github_flavored_markdown.Markdown(text, github_flavored_markdown.WithOptions(github_flavored_markdown.Options{Footnotes: true, Spoilers: true}))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func ExampleMarkdown_options() {
+	text := []byte("See [the docs](https://example.com/docs?utm_source=readme).")
+
+	os.Stdout.Write(github_flavored_markdown.Markdown(text, github_flavored_markdown.WithStripTrackingParams()))
+
+	// Output:
+	// <p>See <a href="https://example.com/docs" rel="nofollow">the docs</a>.</p>
+}
+
+func TestWithMode(t *testing.T) {
+	text := []byte("First line\nsecond line\n\n- item\n  continued\n")
+
+	tests := []struct {
+		mode github_flavored_markdown.Mode
+		want string
+	}{
+		{
+			mode: github_flavored_markdown.DocumentMode,
+			want: "<p>First line\nsecond line</p>\n\n<ul>\n<li>item\ncontinued</li>\n</ul>\n",
+		},
+		{
+			mode: github_flavored_markdown.CommentMode,
+			want: "<p>First line<br>\nsecond line</p>\n\n<ul>\n<li>item<br>\ncontinued</li>\n</ul>\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown(text, github_flavored_markdown.WithMode(test.mode))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestSmartypants(t *testing.T) {
+	text := []byte(`"Quotes" -- 'single' --- dots... 1/2, 3/8 and ` + "`\"code\" --`\n")
+
+	tests := []struct {
+		opts github_flavored_markdown.Options
+		want string
+	}{
+		{
+			opts: github_flavored_markdown.Options{Smartypants: true},
+			want: "<p>“Quotes” – ‘single’ — dots… ½, 3/8 and <code>&#34;code&#34; --</code></p>\n",
+		},
+		{
+			opts: github_flavored_markdown.Options{Smartypants: true, SmartypantsFractions: true, SmartypantsAngledQuotes: true},
+			want: "<p>«Quotes» – ‘single’ — dots… <sup>1</sup>⁄<sub>2</sub>, <sup>3</sup>⁄<sub>8</sub> and <code>&#34;code&#34; --</code></p>\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.MarkdownOptions(text, test.opts)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// OutlineHeading is a heading in a document outline. It is not named
+// Heading, which renders a heading.
+type OutlineHeading struct {
+	HeadingEntry
+
+	// Start and End are the byte offsets of the heading's source lines in
+	// the text, or -1 if they could not be determined.
+	Start, End int
+
+	// Children are the subheadings, up to the next heading of the same or
+	// a higher level.
+	Children []OutlineHeading
+}
+
+// Outline returns the heading tree of text, without rendering it. Anchor
+// names are the same as in the output of Markdown with the same options.
+func Outline(text []byte, opts ...Option) []OutlineHeading {
+	o := newOptions(opts)
+	doc, _ := newRenderer(o).parse(text)
+	entries := headings(doc, o)
+	spans := sourceHeadingSpans(text, o, len(entries))
+
+	flat := make([]OutlineHeading, len(entries))
+	for i, e := range entries {
+		flat[i] = OutlineHeading{HeadingEntry: e, Start: -1, End: -1}
+		if spans != nil {
+			flat[i].Start, flat[i].End = spans[i][0], spans[i][1]
+		}
+	}
+	return outline(flat)
+}
+
+// sourceHeadingSpans returns the byte offsets in text of the source lines of
+// its n parsed headings, or nil if they could not be determined.
+func sourceHeadingSpans(text []byte, opts Options, n int) [][2]int {
+	var offset int
+	if opts.FrontMatter {
+		body, _ := extractFrontMatter(text)
+		offset = len(text) - len(body)
+		text = body
+	}
+	spans := headingSpans(text)
+	if len(spans) != n {
+		return nil
+	}
+	for i := range spans {
+		spans[i][0] += offset
+		spans[i][1] += offset
+	}
+	return spans
+}
+
+// outline nests each heading of flat under the preceding heading of a lower level.
+func outline(flat []OutlineHeading) []OutlineHeading {
+	var out []OutlineHeading
+	for i := 0; i < len(flat); {
+		j := i + 1
+		for j < len(flat) && flat[j].Level > flat[i].Level {
+			j++
+		}
+		h := flat[i]
+		h.Children = outline(flat[i+1 : j])
+		out = append(out, h)
+		i = j
+	}
+	return out
+}
+
+var (
+	atxHeadingRE    = regexp.MustCompile(`^#{1,6}(?:[ \t]|$)`)
+	setextHeadingRE = regexp.MustCompile(`^(?:=+|-+)[ \t]*$`)
+	codeFenceRE     = regexp.MustCompile("^(?:`{3,}|~{3,})")
+)
+
+// headingSpans returns the byte offsets of the lines of text that make up
+// headings, in document order. It recognizes ATX and setext headings outside
+// of code blocks, which is enough to match the parsed headings of most text.
+func headingSpans(text []byte) [][2]int {
+	var spans [][2]int
+	var fence []byte // Opening fence of the current fenced code block, if any.
+	para := -1       // Start of the previous line if it is paragraph text.
+	for start := 0; start < len(text); {
+		end := bytes.IndexByte(text[start:], '\n') + 1
+		if end == 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		line := bytes.TrimRight(text[start:end], "\r\n")
+		trimmed := bytes.TrimLeft(line, " ")
+		indented := len(line)-len(trimmed) >= 4
+
+		switch {
+		case fence != nil:
+			if !indented && bytes.HasPrefix(trimmed, fence) && len(bytes.TrimLeft(trimmed, string(fence[:1])+" \t")) == 0 {
+				fence = nil
+			}
+		case indented && para < 0:
+			// Indented code.
+		case codeFenceRE.Match(trimmed):
+			fence = codeFenceRE.Find(trimmed)
+			para = -1
+		case atxHeadingRE.Match(trimmed):
+			spans = append(spans, [2]int{start, start + len(line)})
+			para = -1
+		case para >= 0 && setextHeadingRE.Match(trimmed):
+			spans = append(spans, [2]int{para, start + len(line)})
+			para = -1
+		case len(bytes.TrimSpace(line)) == 0:
+			para = -1
+		default:
+			para = start
+		}
+		start = end
+	}
+	return spans
+}
@@ -0,0 +1,45 @@
+package github_flavored_markdown_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestOutline(t *testing.T) {
+	text := "# Title\n\nIntro.\n\n```\n# not a heading\n```\n\nSetup\n-----\n\n### Deep\n\n## Usage\n\n# Other\n"
+
+	type entry = github_flavored_markdown.HeadingEntry
+	want := []github_flavored_markdown.OutlineHeading{
+		{
+			HeadingEntry: entry{Level: 1, Title: "Title", Anchor: "title"}, Start: 0, End: 7,
+			Children: []github_flavored_markdown.OutlineHeading{
+				{
+					HeadingEntry: entry{Level: 2, Title: "Setup", Anchor: "setup"}, Start: 42, End: 53,
+					Children: []github_flavored_markdown.OutlineHeading{
+						{HeadingEntry: entry{Level: 3, Title: "Deep", Anchor: "deep"}, Start: 55, End: 63},
+					},
+				},
+				{HeadingEntry: entry{Level: 2, Title: "Usage", Anchor: "usage"}, Start: 65, End: 73},
+			},
+		},
+		{HeadingEntry: entry{Level: 1, Title: "Other", Anchor: "other"}, Start: 75, End: 82},
+	}
+
+	got := github_flavored_markdown.Outline([]byte(text))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %+v\nwant %+v", got, want)
+	}
+	if s := text[want[0].Children[0].Start:want[0].Children[0].End]; s != "Setup\n-----" {
+		t.Errorf("got source %q", s)
+	}
+}
+
+func TestOutlineFrontMatter(t *testing.T) {
+	text := "---\ntitle: x\n---\n# A\n"
+	got := github_flavored_markdown.Outline([]byte(text), github_flavored_markdown.WithFrontMatter())
+	if len(got) != 1 || text[got[0].Start:got[0].End] != "# A" {
+		t.Errorf("got %+v", got)
+	}
+}
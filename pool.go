@@ -0,0 +1,31 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize is the capacity above which buffers are not returned
+// to bufferPool, so that rendering one large document doesn't keep a large
+// buffer alive.
+const maxPooledBufferSize = 256 * 1024
+
+// bufferPool holds scratch buffers for rendering, which reduces allocations
+// and GC pressure when many documents are rendered.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from bufferPool.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buf to bufferPool. Its contents must no longer be used.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,64 @@
+package github_flavored_markdown
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// ImageProxy routes external images through a camo-style image proxy,
+// so that readers don't make requests to third-party hosts.
+type ImageProxy struct {
+	// URL is the template for proxied image URLs. The placeholders
+	// "{sig}", "{hexurl}" and "{url}" are replaced with the signature of
+	// the image URL, the hex-encoded image URL, and the query-escaped
+	// image URL. For camo, use "https://camo.example.com/{sig}/{hexurl}".
+	URL string
+
+	// Sign returns the signature of an image URL. See CamoSigner.
+	// If nil, "{sig}" is replaced with the empty string.
+	Sign func(imageURL string) string
+
+	// Hosts lists the hosts whose images are not proxied, in addition to
+	// the host of the proxy itself.
+	Hosts []string
+}
+
+// CamoSigner returns a signing function for ImageProxy that computes the
+// hex-encoded HMAC-SHA1 of the image URL with key, as camo expects.
+func CamoSigner(key []byte) func(imageURL string) string {
+	return func(imageURL string) string {
+		mac := hmac.New(sha1.New, key)
+		mac.Write([]byte(imageURL))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+// rewrite returns the proxied URL for the image at dest. Relative URLs,
+// non-HTTP URLs and images on an excluded host are returned unchanged.
+func (p *ImageProxy) rewrite(dest string) string {
+	u, err := url.Parse(dest)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return dest
+	}
+	host := u.Hostname()
+	if proxy, err := url.Parse(p.URL); err == nil && strings.EqualFold(proxy.Hostname(), host) {
+		return dest
+	}
+	for _, h := range p.Hosts {
+		if strings.EqualFold(h, host) {
+			return dest
+		}
+	}
+	var sig string
+	if p.Sign != nil {
+		sig = p.Sign(dest)
+	}
+	return strings.NewReplacer(
+		"{sig}", sig,
+		"{hexurl}", hex.EncodeToString([]byte(dest)),
+		"{url}", url.QueryEscape(dest),
+	).Replace(p.URL)
+}
@@ -0,0 +1,35 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestWithImageProxy(t *testing.T) {
+	proxy := github_flavored_markdown.ImageProxy{
+		URL:   "https://camo.example.com/{sig}/{hexurl}",
+		Sign:  github_flavored_markdown.CamoSigner([]byte("secret")),
+		Hosts: []string{"example.org"},
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "![a](http://img.example.net/a.png)",
+			want: `<p><img src="https://camo.example.com/288c22a898da223cd95213d7639bbd1952f99b3c/687474703a2f2f696d672e6578616d706c652e6e65742f612e706e67" alt="a"/></p>` + "\n",
+		},
+		{
+			// Relative images, excluded hosts and links are left alone.
+			text: "![b](b.png) ![c](https://example.org/c.png) [d](http://img.example.net/d.png)",
+			want: `<p><img src="b.png" alt="b"/> <img src="https://example.org/c.png" alt="c"/> <a href="http://img.example.net/d.png" rel="nofollow">d</a></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithImageProxy(proxy))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// References configures linking of references to issues and pull requests,
+// such as #123 and owner/repo#456, and to commits, like GitHub does in comments.
+type References struct {
+	// Repository is the repository, as "owner/repo", that references
+	// without a repository, such as #123, refer to. If empty, they're not linked.
+	Repository string
+
+	// BaseURL is the URL of the GitHub instance that references link to.
+	// If empty, "https://github.com" is used.
+	BaseURL string
+
+	// Resolve, if set, returns the URL of issue or pull request number in repo,
+	// or false if it shouldn't be linked. It's used instead of linking to BaseURL.
+	Resolve func(repo string, number int) (url string, ok bool)
+
+	// DisableIssues turns off linking of issue and pull request references,
+	// for when only commits should be linked.
+	DisableIssues bool
+
+	// Commits links commit SHAs of 7 to 40 hex digits, such as a5c3785ed8,
+	// and owner/repo@a5c3785ed8. The link text is shortened to 7 digits.
+	Commits bool
+
+	// CommitURL is the URL template that commit SHAs link to. "{repo}" and
+	// "{sha}" in it are replaced with the repository and the full SHA.
+	// If empty, "{BaseURL}/{repo}/commit/{sha}" is used.
+	CommitURL string
+}
+
+// commitRE matches commit SHAs, optionally prefixed with owner/repo@,
+// that don't follow a word character.
+var commitRE = regexp.MustCompile(`(?:^|[^\w/@&#])((?:([A-Za-z0-9][A-Za-z0-9-]*/[\w.-]+)@)?([0-9a-f]{7,40}))\b`)
+
+// issueRefRE matches issue references, #123 or owner/repo#123, that don't
+// follow a word character or "&", so that "a#1" and "&#39;" don't match.
+var issueRefRE = regexp.MustCompile(`(?:^|[^\w/#&])((?:([A-Za-z0-9][A-Za-z0-9-]*/[\w.-]+))?#([0-9]+))\b`)
+
+// issueURL returns the URL of issue or pull request number in repo.
+func (refs *References) issueURL(repo string, number int) (string, bool) {
+	if repo == "" {
+		repo = refs.Repository
+	}
+	if repo == "" {
+		return "", false
+	}
+	if refs.Resolve != nil {
+		return refs.Resolve(repo, number)
+	}
+	return fmt.Sprintf("%s/%s/issues/%d", refs.baseURL(), repo, number), true
+}
+
+func (refs *References) baseURL() string {
+	if refs.BaseURL == "" {
+		return "https://github.com"
+	}
+	return strings.TrimSuffix(refs.BaseURL, "/")
+}
+
+// commitURL returns the URL of commit sha in repo.
+func (refs *References) commitURL(repo, sha string) (string, bool) {
+	if repo == "" {
+		repo = refs.Repository
+	}
+	if repo == "" {
+		return "", false
+	}
+	if refs.CommitURL == "" {
+		return fmt.Sprintf("%s/%s/commit/%s", refs.baseURL(), repo, sha), true
+	}
+	return strings.NewReplacer("{repo}", repo, "{sha}", sha).Replace(refs.CommitURL), true
+}
+
+// isSHA reports whether s, which consists of hex digits, looks like a SHA
+// rather than a number or a word, by having both decimal digits and letters.
+func isSHA(s []byte) bool {
+	return bytes.ContainsAny(s, "0123456789") && bytes.ContainsAny(s, "abcdef")
+}
+
+// linkReferences links the references in the text of doc.
+// Text in links is skipped.
+func linkReferences(doc *bf.Node, refs *References) {
+	if !refs.DisableIssues {
+		linkIssues(doc, refs)
+	}
+	if refs.Commits {
+		linkCommits(doc, refs)
+	}
+}
+
+// linkCommits links the commit SHAs in the text of doc.
+func linkCommits(doc *bf.Node, refs *References) {
+	replaceUnlinkedText(doc, func(text []byte) []*bf.Node {
+		return replaceMatches(text, commitRE, func(m [][]byte) (string, bool) {
+			sha := m[3]
+			if !isSHA(sha) {
+				return "", false
+			}
+			url, ok := refs.commitURL(string(m[2]), string(sha))
+			if !ok {
+				return "", false
+			}
+			var prefix string
+			if len(m[2]) > 0 {
+				prefix = string(m[2]) + "@"
+			}
+			return fmt.Sprintf(`<a href="%s" class="commit-link">%s<code>%s</code></a>`, template.HTMLEscapeString(url), template.HTMLEscapeString(prefix), sha[:7]), true
+		})
+	})
+}
+
+// linkIssues links the issue and pull request references in the text of doc.
+func linkIssues(doc *bf.Node, refs *References) {
+	replaceUnlinkedText(doc, func(text []byte) []*bf.Node {
+		return replaceMatches(text, issueRefRE, func(m [][]byte) (string, bool) {
+			number, err := strconv.Atoi(string(m[3]))
+			if err != nil {
+				return "", false
+			}
+			url, ok := refs.issueURL(string(m[2]), number)
+			if !ok {
+				return "", false
+			}
+			return fmt.Sprintf(`<a href="%s" class="issue-link">%s</a>`, template.HTMLEscapeString(url), template.HTMLEscapeString(string(m[1]))), true
+		})
+	})
+}
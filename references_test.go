@@ -0,0 +1,84 @@
+package github_flavored_markdown_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		text string
+		refs github_flavored_markdown.References
+		want string
+	}{
+		{
+			text: "Fixes #123 and golang/go#456.",
+			refs: github_flavored_markdown.References{Repository: "owner/repo"},
+			want: `<p>Fixes <a href="https://github.com/owner/repo/issues/123" class="issue-link" rel="nofollow">#123</a> and <a href="https://github.com/golang/go/issues/456" class="issue-link" rel="nofollow">golang/go#456</a>.</p>` + "\n",
+		},
+		{
+			// Without a base repository, only cross-repository references are linked.
+			text: "#1 a#2 `#3` o/r#4",
+			refs: github_flavored_markdown.References{BaseURL: "https://ghe.example.com/"},
+			want: `<p>#1 a#2 <code>#3</code> <a href="https://ghe.example.com/o/r/issues/4" class="issue-link" rel="nofollow">o/r#4</a></p>` + "\n",
+		},
+		{
+			text: "See #7 and other/repo#8.",
+			refs: github_flavored_markdown.References{
+				Repository: "owner/repo",
+				Resolve: func(repo string, number int) (string, bool) {
+					if repo != "owner/repo" {
+						return "", false
+					}
+					return fmt.Sprintf("https://tracker.example.com/%d", number), true
+				},
+			},
+			want: `<p>See <a href="https://tracker.example.com/7" class="issue-link" rel="nofollow">#7</a> and other/repo#8.</p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithReferences(test.refs))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestCommitReferences(t *testing.T) {
+	tests := []struct {
+		text string
+		refs github_flavored_markdown.References
+		want string
+	}{
+		{
+			text: "Fixed in a5c3785ed8d6a35868bc169f07e40e889087fd2e and golang/go@0f3b2c1.",
+			refs: github_flavored_markdown.References{Repository: "owner/repo", Commits: true},
+			want: `<p>Fixed in <a href="https://github.com/owner/repo/commit/a5c3785ed8d6a35868bc169f07e40e889087fd2e" class="commit-link" rel="nofollow"><code>a5c3785</code></a> and <a href="https://github.com/golang/go/commit/0f3b2c1" class="commit-link" rel="nofollow">golang/go@<code>0f3b2c1</code></a>.</p>` + "\n",
+		},
+		{
+			// Numbers, words and short SHAs are left as is, and so are issues when disabled.
+			text: "1234567 defaced a5c378 #1 a5c3785",
+			refs: github_flavored_markdown.References{
+				Repository:    "owner/repo",
+				DisableIssues: true,
+				Commits:       true,
+				CommitURL:     "https://git.example.com/{repo}/-/commit/{sha}",
+			},
+			want: `<p>1234567 defaced a5c378 #1 <a href="https://git.example.com/owner/repo/-/commit/a5c3785" class="commit-link" rel="nofollow"><code>a5c3785</code></a></p>` + "\n",
+		},
+		{
+			// Commits are only linked when enabled.
+			text: "a5c3785 #1",
+			refs: github_flavored_markdown.References{Repository: "owner/repo"},
+			want: `<p>a5c3785 <a href="https://github.com/owner/repo/issues/1" class="issue-link" rel="nofollow">#1</a></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithReferences(test.refs))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
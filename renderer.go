@@ -0,0 +1,37 @@
+package github_flavored_markdown
+
+import "io"
+
+// Renderer renders GitHub Flavored Markdown with a fixed set of options.
+// The options, and the sanitization policy they select, are processed once
+// by NewRenderer instead of on every call, which makes a Renderer cheaper
+// than Markdown for rendering many documents with the same options.
+// A Renderer is safe for concurrent use by multiple goroutines.
+type Renderer struct {
+	opts Options
+}
+
+// NewRenderer returns a Renderer that renders with the given options.
+func NewRenderer(opts ...Option) *Renderer {
+	return NewRendererOptions(newOptions(opts))
+}
+
+// NewRendererOptions returns a Renderer that renders with opts.
+// The options must not be modified while the Renderer is in use.
+func NewRendererOptions(opts Options) *Renderer {
+	if opts.Policy == nil {
+		// Select the policy once, rather than for every render.
+		opts.Policy = opts.sanitizer()
+	}
+	return &Renderer{opts: opts}
+}
+
+// Render renders text like Markdown.
+func (r *Renderer) Render(text []byte) []byte {
+	return MarkdownOptions(text, r.opts)
+}
+
+// RenderTo renders text like MarkdownTo, writing the output to w.
+func (r *Renderer) RenderTo(w io.Writer, text []byte) error {
+	return markdownTo(w, text, r.opts)
+}
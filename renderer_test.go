@@ -0,0 +1,52 @@
+package github_flavored_markdown_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestRenderer(t *testing.T) {
+	text := []byte("# Title\n\nHello <script>alert();</script> **world** :smile: [irc](irc://example.com/go).\n")
+	opts := []github_flavored_markdown.Option{github_flavored_markdown.WithEmoji(), github_flavored_markdown.WithURLSchemes("irc")}
+	want := github_flavored_markdown.Markdown(text, opts...)
+
+	r := github_flavored_markdown.NewRenderer(opts...)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := r.Render(text); !bytes.Equal(got, want) {
+				t.Errorf("Render:\ngot %q\nwant %q", got, want)
+			}
+			var buf bytes.Buffer
+			if err := r.RenderTo(&buf, text); err != nil {
+				t.Error(err)
+			}
+			if got := buf.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf("RenderTo:\ngot %q\nwant %q", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Unsanitized output.
+	r = github_flavored_markdown.NewRenderer(github_flavored_markdown.Unsafe())
+	if got, want := r.Render(text), github_flavored_markdown.MarkdownTrusted(text); !bytes.Equal(got, want) {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func BenchmarkRendererParallel(b *testing.B) {
+	text := []byte("Thanks @octocat, this **looks good** to me. See [the docs](https://example.com/docs).\n\n- [x] Tests\n- [ ] Changelog\n")
+	r := github_flavored_markdown.NewRenderer(github_flavored_markdown.WithURLSchemes("irc"))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			r.Render(text)
+		}
+	})
+}
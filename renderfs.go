@@ -0,0 +1,119 @@
+package github_flavored_markdown
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// RenderFS renders the Markdown files of src, the files with a .md
+// extension, to complete HTML pages in the directory dst, like the pages
+// that Handler serves. Each page is written to the path of its file in src,
+// with the extension replaced by .html, except that README.md files are
+// written to index.html, so that links to directories work when dst is
+// served by a web server.
+//
+// Relative links to Markdown files in the pages are rewritten to link to
+// the pages instead, and the local images that pages show are copied from
+// src to the same path in dst. Other files are not copied. The rewriting is
+// done before that of Options.LinkRewriter, if set.
+func RenderFS(src fs.FS, dst string, opts ...Option) error {
+	o := newOptions(opts)
+	copied := make(map[string]bool) // Images that have been copied, by name.
+	return fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".md" {
+			return err
+		}
+		text, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		title, ok := Title(text, WithOptions(o))
+		if !ok {
+			title = path.Base(name)
+		}
+
+		var images []string // Local images of the page.
+		o := o
+		rewrite := o.LinkRewriter
+		o.LinkRewriter = func(dest string, isImage bool) string {
+			if target, ok := localTarget(name, dest); ok {
+				switch {
+				case isImage:
+					images = append(images, target)
+				case path.Ext(target) == ".md":
+					dest = pageLink(dest)
+				}
+			}
+			if rewrite != nil {
+				dest = rewrite(dest, isImage)
+			}
+			return dest
+		}
+		if err := writeFile(dst, pageName(name), Page(title, MarkdownOptions(text, o))); err != nil {
+			return err
+		}
+
+		for _, image := range images {
+			if copied[image] {
+				continue
+			}
+			copied[image] = true
+			b, err := fs.ReadFile(src, image)
+			if err != nil {
+				// Broken images are left broken, as they are in src.
+				continue
+			}
+			if err := writeFile(dst, image, b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// localTarget returns the name of the file in the file system of the
+// document name that dest, a link or image destination in the document,
+// refers to, if it's a relative URL within the file system.
+func localTarget(name, dest string) (string, bool) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	target := path.Join(path.Dir(name), u.Path)
+	return target, fs.ValidPath(target)
+}
+
+// pageLink returns dest, a relative link to a Markdown file, rewritten to
+// link to the page that RenderFS renders the file to.
+func pageLink(dest string) string {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return dest
+	}
+	u.Path = pageName(u.Path)
+	return u.String()
+}
+
+// pageName returns the name of the page that the Markdown file name is
+// rendered to.
+func pageName(name string) string {
+	dir, file := path.Split(name)
+	if file == "README.md" {
+		return dir + "index.html"
+	}
+	return dir + strings.TrimSuffix(file, ".md") + ".html"
+}
+
+// writeFile writes data to the file name, a slash-separated path in the
+// directory dir, creating the directories it's in if needed.
+func writeFile(dir, name string, data []byte) error {
+	name = filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0666)
+}
@@ -0,0 +1,72 @@
+package github_flavored_markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestRenderFS(t *testing.T) {
+	src := fstest.MapFS{
+		"README.md":       {Data: []byte("# Project\n\nSee the [guide](docs/guide.md#usage), [site](https://example.com/a.md) and ![logo](img/logo.png).\n")},
+		"docs/guide.md":   {Data: []byte("Back to the [start](../README.md).\n\n![logo](../img/logo.png) ![missing](missing.png)\n")},
+		"img/logo.png":    {Data: []byte("PNG")},
+		"img/unused.png":  {Data: []byte("PNG")},
+		"notes/draft.txt": {Data: []byte("draft")},
+	}
+	dst := t.TempDir()
+	if err := github_flavored_markdown.RenderFS(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	filepath.Walk(dst, func(name string, fi os.FileInfo, err error) error {
+		if err == nil && !fi.IsDir() {
+			rel, _ := filepath.Rel(dst, name)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if got, want := strings.Join(files, " "), "docs/guide.html img/logo.png index.html"; got != want {
+		t.Errorf("got files %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{
+			name: "index.html",
+			want: []string{
+				"<title>Project</title>",
+				`<a href="docs/guide.html#usage" rel="nofollow">guide</a>`,
+				`<a href="https://example.com/a.md" rel="nofollow">site</a>`,
+				`<img src="img/logo.png" alt="logo"/>`,
+			},
+		},
+		{
+			name: "docs/guide.html",
+			want: []string{
+				"<title>guide.md</title>",
+				`<a href="../index.html" rel="nofollow">start</a>`,
+				`<img src="../img/logo.png" alt="logo"/>`,
+			},
+		},
+	}
+	for _, test := range tests {
+		b, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(test.name)))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for _, want := range test.want {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s doesn't contain %q:\n%s", test.name, want, b)
+			}
+		}
+	}
+}
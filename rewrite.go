@@ -0,0 +1,52 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// rewriteHTML parses the HTML fragment b, calls f with a <body> element
+// containing the parsed nodes, and returns the rendered children of body.
+func rewriteHTML(b []byte, f func(body *html.Node)) []byte {
+	body := &html.Node{Type: html.ElementNode, Data: atom.Body.String(), DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(bytes.NewReader(b), body)
+	if err != nil {
+		return nil
+	}
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	f(body)
+	var buf bytes.Buffer
+	for n := body.FirstChild; n != nil; n = n.NextSibling {
+		html.Render(&buf, n)
+	}
+	return buf.Bytes()
+}
+
+// dirAutoElements are the block elements that get a dir="auto" attribute
+// with Options.DirAuto.
+var dirAutoElements = map[atom.Atom]bool{
+	atom.P: true, atom.Li: true, atom.Blockquote: true, atom.Td: true, atom.Th: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// dirAuto adds a dir="auto" attribute to block elements within n that
+// don't have a dir attribute, so the browser determines the direction of each
+// from its content.
+func dirAuto(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		dirAuto(c)
+	}
+	if n.Type != html.ElementNode || !dirAutoElements[n.DataAtom] {
+		return
+	}
+	for _, a := range n.Attr {
+		if a.Key == "dir" {
+			return
+		}
+	}
+	n.Attr = append([]html.Attribute{{Key: "dir", Val: "auto"}}, n.Attr...)
+}
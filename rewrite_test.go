@@ -0,0 +1,31 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestDirAuto(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "שלום, run `go test`.\n\n- item\n",
+			want: `<p dir="auto">שלום, run <code>go test</code>.</p>` + "\n\n" + `<ul>` + "\n" + `<li dir="auto">item</li>` + "\n" + `</ul>` + "\n",
+		},
+		{
+			// Isolated spans are kept.
+			text: `مرحبا <bdi dir="ltr">x</bdi>`,
+			want: `<p dir="auto">مرحبا <bdi dir="ltr">x</bdi></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		opts := github_flavored_markdown.Options{DirAuto: true}
+		if got := string(github_flavored_markdown.MarkdownOptions([]byte(test.text), opts)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"testing"
 
 	"github.com/microcosm-cc/bluemonday"
-	"github.com/russross/blackfriday"
+	bf "gopkg.in/russross/blackfriday.v2"
 )
 
 // In this test, nothing should be sanitized away.
@@ -46,10 +46,9 @@ index dc83bf7..5260a7d 100644
 ` + "```" + `
 `)
 
-	htmlFlags := 0
-	renderer := &renderer{Html: blackfriday.HtmlRenderer(htmlFlags, "", "").(*blackfriday.Html)}
+	renderer := newRenderer(Options{})
 
-	unsanitized := blackfriday.Markdown(text, renderer, extensions)
+	unsanitized := bf.Run(text, bf.WithRenderer(renderer), bf.WithExtensions(extensions))
 
 	// GitHub Flavored Markdown-like sanitization policy.
 	p := bluemonday.UGCPolicy()
@@ -128,6 +127,95 @@ func TestSanitizeAnchorName(t *testing.T) {
 	}
 }
 
+func TestMarkdownTrusted(t *testing.T) {
+	text := `Hello <script>alert();</script> <span onclick="f()">world</span>.`
+	want := `<p>Hello <script>alert();</script> <span onclick="f()">world</span>.</p>` + "\n"
+
+	if got := string(MarkdownTrusted([]byte(text))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+	if got := string(Markdown([]byte(text), Unsafe())); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestWithPolicy(t *testing.T) {
+	text := `<video src="https://example.com/a.mp4" controls></video> <figure>x</figure>`
+
+	// The default policy removes video.
+	if got, want := string(Markdown([]byte(text))), `<p> <figure>x</figure></p>`+"\n"; got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	p := NewPolicy()
+	p.AllowAttrs("src").OnElements("video")
+	p.AllowAttrs("controls").Matching(regexp.MustCompile(`^$`)).OnElements("video")
+	want := `<p><video src="https://example.com/a.mp4" controls=""></video> <figure>x</figure></p>` + "\n"
+	if got := string(Markdown([]byte(text), WithPolicy(p))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestDetails(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "<details>\n<summary>Click <b>me</b></summary>\n\nHidden **text**\n\n</details>\n",
+			want: "<details>\n<summary>Click <b>me</b></summary>\n<p>Hidden <strong>text</strong></p>\n</details>\n",
+		},
+		{
+			// A closing tag ends the paragraph before it.
+			text: "<details open>\n<summary>Summary</summary>\n\nText\n</details>\n",
+			want: `<details open="">` + "\n" + `<summary>Summary</summary>` + "\n" + `<p>Text</p>` + "\n" + `</details>` + "\n",
+		},
+		{
+			// Other attributes are removed.
+			text: `<details open="open" onclick="f()"><summary onclick="g()">Summary</summary>Text</details>`,
+			want: `<details open="open"><summary>Summary</summary>Text</details>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(Markdown([]byte(test.text))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestWithURLSchemes(t *testing.T) {
+	text := "[irc](irc://irc.libera.chat/go) [magnet](magnet:?xt=urn:btih:abc) [note](notes://a/b) [js](javascript:void)"
+
+	// The default policy removes links with unknown schemes.
+	want := `<p>irc magnet note js</p>` + "\n"
+	if got := string(Markdown([]byte(text))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	want = `<p><a href="irc://irc.libera.chat/go" rel="nofollow">irc</a> <a href="magnet:?xt=urn:btih:abc" rel="nofollow">magnet</a> <a href="notes://a/b" rel="nofollow">note</a> js</p>` + "\n"
+	if got := string(Markdown([]byte(text), WithURLSchemes("irc", "magnet", "notes:", "javascript"))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+// Large input is sanitized as it is rendered, with the same result.
+func TestSanitizeStream(t *testing.T) {
+	text := bytes.Repeat([]byte("Hello <script>alert();</script> <span onclick=\"f()\">**world**</span>.\n\n"), streamMinSize/32)
+	want := Options{}.sanitizer().SanitizeBytes(MarkdownTrusted(text))
+
+	if got := Markdown(text); !bytes.Equal(got, want) {
+		t.Errorf("Markdown: got %d bytes, want %d bytes", len(got), len(want))
+	}
+	var buf bytes.Buffer
+	if err := MarkdownTo(&buf, text, WithMaxInputSize(-1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("MarkdownTo: got %d bytes, want %d bytes", len(got), len(want))
+	}
+}
+
 // TODO: Factor out.
 func diff(b1, b2 []byte) (data []byte, err error) {
 	f1, err := ioutil.TempFile("", "")
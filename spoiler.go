@@ -0,0 +1,102 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+var spoilerMarker = []byte("||")
+
+// spoilers wraps text between pairs of "||" markers in spoiler spans.
+// Markers are paired among the inline children of a single block, so a spoiler
+// may contain emphasis or links, but can't span blocks. An unpaired trailing
+// marker is left as is. Table cells are skipped, since "||" there is more likely
+// an empty cell than a spoiler.
+func spoilers(doc *bf.Node) {
+	var parents []*bf.Node
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering {
+			return bf.GoToNext
+		}
+		switch node.Type {
+		case bf.Table, bf.CodeBlock, bf.HTMLBlock:
+			return bf.SkipChildren
+		case bf.Text:
+			if node.Parent != nil && bytes.Contains(node.Literal, spoilerMarker) {
+				if len(parents) == 0 || parents[len(parents)-1] != node.Parent {
+					parents = append(parents, node.Parent)
+				}
+			}
+		}
+		return bf.GoToNext
+	})
+
+	for _, parent := range parents {
+		wrapSpoilers(parent)
+	}
+}
+
+// wrapSpoilers splits the text children of parent at paired "||" markers,
+// inserting spoiler span open and close tags between them.
+func wrapSpoilers(parent *bf.Node) {
+	var count int
+	for c := parent.FirstChild; c != nil; c = c.Next {
+		if c.Type == bf.Text {
+			count += bytes.Count(c.Literal, spoilerMarker)
+		}
+	}
+	usable := count - count%2
+
+	var open bool
+	for c := parent.FirstChild; c != nil && usable > 0; {
+		next := c.Next
+		if c.Type != bf.Text {
+			c = next
+			continue
+		}
+		parts := bytes.Split(c.Literal, spoilerMarker)
+		c.Literal = parts[0]
+		for i, part := range parts[1:] {
+			if usable == 0 {
+				// Put back the remaining unpaired marker.
+				rest := bytes.Join(parts[i+1:], spoilerMarker)
+				last := lastTextBefore(parent, next)
+				last.Literal = append(append(last.Literal, spoilerMarker...), rest...)
+				break
+			}
+			usable--
+
+			tag := bf.NewNode(bf.HTMLSpan)
+			if open {
+				tag.Literal = []byte(`</span>`)
+			} else {
+				tag.Literal = []byte(`<span class="spoiler">`)
+			}
+			open = !open
+			insertBefore(parent, next, tag)
+
+			text := bf.NewNode(bf.Text)
+			text.Literal = part
+			insertBefore(parent, next, text)
+		}
+		c = next
+	}
+}
+
+// insertBefore inserts node into parent before next, or at the end if next is nil.
+func insertBefore(parent, next, node *bf.Node) {
+	if next != nil {
+		next.InsertBefore(node)
+	} else {
+		parent.AppendChild(node)
+	}
+}
+
+// lastTextBefore returns the last child of parent before next, which must be a text node.
+func lastTextBefore(parent, next *bf.Node) *bf.Node {
+	if next != nil {
+		return next.Prev
+	}
+	return parent.LastChild
+}
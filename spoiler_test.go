@@ -0,0 +1,36 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestSpoilers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "The butler ||did *it*|| after all.",
+			want: `<p>The butler <span class="spoiler">did <em>it</em></span> after all.</p>` + "\n",
+		},
+		{
+			// Not inside code spans, and an unpaired marker is left alone.
+			text: "`||x||` and || alone",
+			want: `<p><code>||x||</code> and || alone</p>` + "\n",
+		},
+		{
+			// A double pipe in a table row is a cell boundary, not a spoiler.
+			text: "| a | b |\n|---|---|\n| x || y |\n",
+			want: "<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n\n<tbody>\n<tr>\n<td>x</td>\n<td></td>\n</tr>\n</tbody>\n</table>\n",
+		},
+	}
+
+	for _, test := range tests {
+		opts := github_flavored_markdown.Options{Spoilers: true}
+		if got := string(github_flavored_markdown.MarkdownOptions([]byte(test.text), opts)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"html"
+	"time"
+	"unicode"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// wordsPerMinute is the reading speed assumed for Statistics.ReadingTime.
+const wordsPerMinute = 200
+
+// Statistics are statistics about a document, as returned by Stats.
+type Statistics struct {
+	Words       int           // Words of prose, excluding code blocks, URLs and alt text.
+	Characters  int           // Characters in those words, excluding spaces.
+	CodeBlocks  int           // Number of code blocks.
+	Images      int           // Number of images.
+	ReadingTime time.Duration // Estimated time to read the words.
+}
+
+// Stats returns statistics about GitHub Flavored Markdown text, computed
+// from the parsed document. Han, Hiragana and Katakana characters are
+// counted as words of their own, since those scripts don't separate words
+// with spaces.
+func Stats(text []byte, opts ...Option) Statistics {
+	var s Statistics
+	doc := Parse(text, opts...)
+	doc.Node.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering {
+			return bf.GoToNext
+		}
+		switch node.Type {
+		case bf.CodeBlock:
+			s.CodeBlocks++
+		case bf.Image:
+			s.Images++
+			return bf.SkipChildren
+		case bf.Link:
+			if bytes.Equal(bytes.TrimPrefix(node.LinkData.Destination, []byte("mailto:")), []byte(collectLiteral(node))) {
+				// An autolink, whose text is a URL.
+				return bf.SkipChildren
+			}
+		case bf.Text, bf.Code:
+			s.count(html.UnescapeString(string(node.Literal)))
+		}
+		return bf.GoToNext
+	})
+	s.ReadingTime = time.Duration(s.Words) * time.Minute / wordsPerMinute
+	return s
+}
+
+// count adds the words and characters of text to s.
+func (s *Statistics) count(text string) {
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			inWord = false
+			continue
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			s.Words++
+			inWord = false
+		case !inWord:
+			s.Words++
+			inWord = true
+		}
+		s.Characters++
+	}
+}
+
+// collectLiteral returns the concatenated literals of the descendants of node.
+func collectLiteral(node *bf.Node) string {
+	var buf bytes.Buffer
+	node.Walk(func(n *bf.Node, entering bool) bf.WalkStatus {
+		if entering && n != node {
+			buf.Write(n.Literal)
+		}
+		return bf.GoToNext
+	})
+	return buf.String()
+}
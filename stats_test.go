@@ -0,0 +1,24 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestStats(t *testing.T) {
+	text := "# Hello world\n\nSee [the docs](https://example.com/docs), https://example.org and `go get`.\n\n" +
+		"![A diagram](d.png)\n\n```go\nfunc main() {}\n```\n\n日本語です\n"
+	want := github_flavored_markdown.Statistics{
+		Words:       15, // Hello world See the docs, and go get. 日 本 語 で す
+		Characters:  35,
+		CodeBlocks:  1,
+		Images:      1,
+		ReadingTime: 15 * time.Minute / 200,
+	}
+
+	if got := github_flavored_markdown.Stats([]byte(text)); got != want {
+		t.Errorf("\ngot  %+v\nwant %+v", got, want)
+	}
+}
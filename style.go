@@ -0,0 +1,57 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"sync"
+)
+
+// styleFiles are the stylesheets that CSS returns: the gfm.css of gfmstyle,
+// and styles for the elements rendered by the options.
+//
+//go:embed gfmstyle/_data/gfm.css style.css
+var styleFiles embed.FS
+
+// CSS returns a stylesheet for the rendered output, for use inside an
+// element with the class "markdown-body". It includes the styles of the
+// gfmstyle package, which cover the classes of headings, anchors and
+// highlighted code, and styles for the elements rendered by the options,
+// such as alerts, spoilers, footnotes and line numbers.
+func CSS() []byte {
+	return append([]byte(nil), stylesheet()...)
+}
+
+var (
+	stylesheetOnce sync.Once
+	stylesheetData []byte
+)
+
+// stylesheet returns the stylesheet that CSS returns copies of.
+func stylesheet() []byte {
+	stylesheetOnce.Do(func() {
+		for _, name := range []string{"gfmstyle/_data/gfm.css", "style.css"} {
+			b, err := styleFiles.ReadFile(name)
+			if err != nil {
+				panic(err)
+			}
+			stylesheetData = append(stylesheetData, b...)
+		}
+	})
+	return stylesheetData
+}
+
+// Page returns body, rendered Markdown, as a complete HTML page with the
+// given title, styled with the stylesheet of CSS, which it embeds. The title
+// is escaped; body is inserted as is.
+func Page(title string, body []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>%s</title><style>%s</style></head>
+<body><article class="markdown-body entry-content" style="padding: 30px;">
+`, template.HTMLEscapeString(title), stylesheet())
+	buf.Write(body)
+	buf.WriteString("</article></body></html>\n")
+	return buf.Bytes()
+}
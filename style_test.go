@@ -0,0 +1,72 @@
+package github_flavored_markdown_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+// TestCSS tests that CSS has styles for the classes of the rendered output.
+func TestCSS(t *testing.T) {
+	text := []byte(`# Title
+
+> [!NOTE]
+> Note.
+
+> [!CAUTION]
+> Caution.
+
+Hello @user, see #1 and ||a spoiler|| :smile:, $x$ and[^1].
+
+- [ ] task
+
+` + "```go {2}\na := 1\nb := 2\n```" + `
+
+[^1]: A footnote.
+`)
+	out := github_flavored_markdown.Markdown(text,
+		github_flavored_markdown.WithTOC(),
+		github_flavored_markdown.WithFootnotes(),
+		github_flavored_markdown.WithSpoilers(),
+		github_flavored_markdown.WithEmoji(),
+		github_flavored_markdown.WithMath(),
+		github_flavored_markdown.WithLineNumbers(),
+		github_flavored_markdown.WithCodeLangBadge(),
+		github_flavored_markdown.WithMentions(func(name string) (string, bool) { return "/" + name, true }),
+		github_flavored_markdown.WithReferences(github_flavored_markdown.References{Repository: "a/b"}),
+	)
+	css := github_flavored_markdown.CSS()
+
+	classes := make(map[string]bool)
+	for _, m := range regexp.MustCompile(`class="([^"]*)"`).FindAllSubmatch(out, -1) {
+		for _, class := range strings.Fields(string(m[1])) {
+			classes[class] = true
+		}
+	}
+	if len(classes) < 10 {
+		t.Fatalf("only %d classes in the output:\n%s", len(classes), out)
+	}
+	for class := range classes {
+		switch {
+		case strings.HasPrefix(class, "highlight-") && class != "highlight-title":
+			continue // Code block languages.
+		case strings.HasPrefix(class, "octicon-") && class != "octicon-link":
+			continue // Icons of the octicons font.
+		}
+		if !regexp.MustCompile(`\.` + regexp.QuoteMeta(class) + `\b`).Match(css) {
+			t.Errorf("no style for class %q", class)
+		}
+	}
+}
+
+func TestPage(t *testing.T) {
+	page := github_flavored_markdown.Page("A & B", []byte("<p>text</p>\n"))
+	for _, want := range []string{"<title>A &amp; B</title>", ".markdown-body", ".markdown-alert", "<p>text</p>\n</article>"} {
+		if !bytes.Contains(page, []byte(want)) {
+			t.Errorf("page doesn't contain %q:\n%s", want, page)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// svgRE matches the start of an svg element.
+var svgRE = regexp.MustCompile(`(?i)<svg[\t\n\f\r />]`)
+
+// svgElements are the SVG elements that SVGSanitize keeps, lowercase.
+var svgElements = []string{
+	"svg", "g", "defs", "symbol", "use", "title", "desc",
+	"path", "rect", "circle", "ellipse", "line", "polyline", "polygon",
+	"text", "tspan", "textpath",
+	"lineargradient", "radialgradient", "stop", "clippath", "mask", "pattern", "marker",
+}
+
+// svgAttrs are the SVG attributes that SVGSanitize keeps, lowercase.
+var svgAttrs = []string{
+	"viewbox", "width", "height", "x", "y", "x1", "y1", "x2", "y2", "cx", "cy", "r", "rx", "ry",
+	"d", "points", "pathlength", "transform", "preserveaspectratio", "xmlns", "version", "role",
+	"fill", "fill-opacity", "fill-rule", "stroke", "stroke-width", "stroke-opacity",
+	"stroke-linecap", "stroke-linejoin", "stroke-dasharray", "stroke-dashoffset", "stroke-miterlimit",
+	"opacity", "color", "clip-path", "clip-rule", "mask", "visibility", "display",
+	"marker-start", "marker-mid", "marker-end", "markerwidth", "markerheight", "refx", "refy", "orient",
+	"font-family", "font-size", "font-style", "font-weight", "text-anchor", "dominant-baseline",
+	"letter-spacing", "dx", "dy", "textlength", "startoffset",
+	"offset", "stop-color", "stop-opacity", "gradientunits", "gradienttransform", "spreadmethod", "fx", "fy",
+	"patternunits", "patterntransform", "clippathunits", "maskunits", "markerunits",
+}
+
+// svgValueRE matches SVG attribute values without references to other
+// documents. Functional notation is limited to transforms, colors and
+// references to elements within the document.
+var svgValueRE = regexp.MustCompile(`^(?:[^()<>]|url\(#[\w.:-]+\)|(?:matrix|translate|scale|rotate|skewX|skewY|rgb|rgba|hsl|hsla)\([^()]*\))*$`)
+
+// svgRemovedElements are the elements within inline SVG that are removed
+// along with their content.
+var svgRemovedElements = map[string]bool{"script": true, "style": true, "foreignobject": true}
+
+// allowSVG extends p to allow sanitized inline SVG.
+func allowSVG(p *bluemonday.Policy) {
+	p.AllowNoAttrs().OnElements(svgElements...)
+	p.AllowAttrs(svgAttrs...).Matching(svgValueRE).OnElements(svgElements...)
+	p.AllowAttrs("id", "class").Matching(regexp.MustCompile(`^[\w -]+$`)).OnElements(svgElements...)
+	p.AllowAttrs("href", "xlink:href").Matching(regexp.MustCompile(`^#[\w.:-]+$`)).OnElements("use", "textpath")
+}
+
+// svg rewrites the inline SVG within n for r.opts.SVG.
+func (r *renderer) svg(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && c.DataAtom == atom.Svg {
+			switch r.opts.SVG {
+			case SVGSanitize:
+				sanitizeSVG(c)
+			case SVGRasterize:
+				r.rasterizeSVG(c)
+			}
+		} else {
+			r.svg(c)
+		}
+		c = next
+	}
+}
+
+// sanitizeSVG removes the scripts, styles, <foreignObject> elements and
+// event handler attributes within the svg element n. The rest is left to
+// the policy of allowSVG.
+func sanitizeSVG(n *html.Node) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		if !strings.HasPrefix(strings.ToLower(a.Key), "on") {
+			attrs = append(attrs, a)
+		}
+	}
+	n.Attr = attrs
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && svgRemovedElements[strings.ToLower(c.Data)] {
+			n.RemoveChild(c)
+		} else {
+			sanitizeSVG(c)
+		}
+		c = next
+	}
+}
+
+// rasterizeSVG replaces the svg element n with an image whose source is
+// returned by r.opts.RasterizeSVG, or removes it on error.
+func (r *renderer) rasterizeSVG(n *html.Node) {
+	var buf bytes.Buffer
+	if err := html.Render(&buf, n); err == nil && r.opts.RasterizeSVG != nil {
+		if src, err := r.opts.RasterizeSVG(buf.Bytes()); err == nil && src != "" {
+			img := &html.Node{Type: html.ElementNode, Data: atom.Img.String(), DataAtom: atom.Img,
+				Attr: []html.Attribute{{Key: "src", Val: src}}}
+			if t := svgTitle(n); t != "" {
+				img.Attr = append(img.Attr, html.Attribute{Key: "alt", Val: t})
+			}
+			n.Parent.InsertBefore(img, n)
+		}
+	}
+	n.Parent.RemoveChild(n)
+}
+
+// svgTitle returns the text of the title element of the svg element n.
+func svgTitle(n *html.Node) string {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "title" && c.FirstChild != nil && c.FirstChild.Type == html.TextNode {
+			return c.FirstChild.Data
+		}
+	}
+	return ""
+}
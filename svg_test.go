@@ -0,0 +1,50 @@
+package github_flavored_markdown_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestSVG(t *testing.T) {
+	text := `<svg width="10" height="10" viewBox="0 0 10 10" onload="f()"><title>Dot</title><script>alert(1)</script><foreignObject><div>x</div></foreignObject>` +
+		`<defs><linearGradient id="g"><stop offset="0" stop-color="red"/></linearGradient></defs>` +
+		`<circle cx="5" cy="5" r="4" fill="url(#g)" transform="rotate(45)" onclick="f()"/><use href="#c"/><rect width="1" fill="url(https://example.com/)"/></svg>` + "\n"
+
+	rasterize := func(svg []byte) (string, error) {
+		return "data:image/png;base64,iVBORw0KGgo=", nil
+	}
+	fail := func(svg []byte) (string, error) {
+		return "", errors.New("fail")
+	}
+
+	tests := []struct {
+		opts []github_flavored_markdown.Option
+		want string
+	}{
+		{
+			// Removed by default, apart from the text of foreign elements.
+			want: `<p><div>x</div></p>` + "\n",
+		},
+		{
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithSVGSanitize()},
+			want: `<p><svg width="10" height="10" viewbox="0 0 10 10"><title>Dot</title><defs><lineargradient id="g"><stop offset="0" stop-color="red"></stop></lineargradient></defs>` +
+				`<circle cx="5" cy="5" r="4" fill="url(#g)" transform="rotate(45)"></circle><use href="#c"></use><rect width="1"></rect></svg></p>` + "\n",
+		},
+		{
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithSVGRasterize(rasterize)},
+			want: `<p><img src="data:image/png;base64,iVBORw0KGgo=" alt="Dot"/></p>` + "\n",
+		},
+		{
+			opts: []github_flavored_markdown.Option{github_flavored_markdown.WithSVGRasterize(fail)},
+			want: `<p></p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(text), test.opts...)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+var (
+	brTagRE      = regexp.MustCompile(`^<br\s*/?>$`)
+	bulletItemRE = regexp.MustCompile(`^[-*+] `)
+	orderedRE    = regexp.MustCompile(`^[0-9]{1,9}[.)] `)
+)
+
+// richTableCells turns table cells whose content is separated by <br> tags
+// into block content. Each <br>-separated segment becomes a paragraph,
+// and consecutive segments starting with list markers become a list.
+// Cells without a <br> are left as is.
+//
+// This is not part of GitHub Flavored Markdown, which only allows inline
+// content in table cells.
+func richTableCells(doc *bf.Node) {
+	var cells []*bf.Node
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if entering && node.Type == bf.TableCell {
+			cells = append(cells, node)
+			return bf.SkipChildren
+		}
+		return bf.GoToNext
+	})
+	for _, cell := range cells {
+		richTableCell(cell)
+	}
+}
+
+func richTableCell(cell *bf.Node) {
+	// Split the children into segments at <br> tags.
+	var segments [][]*bf.Node
+	var segment []*bf.Node
+	var hasBr bool
+	for c := cell.FirstChild; c != nil; c = c.Next {
+		if c.Type == bf.HTMLSpan && brTagRE.Match(bytes.TrimSpace(c.Literal)) {
+			segments = append(segments, segment)
+			segment = nil
+			hasBr = true
+			continue
+		}
+		segment = append(segment, c)
+	}
+	if !hasBr {
+		return
+	}
+	segments = append(segments, segment)
+
+	for c := cell.FirstChild; c != nil; {
+		next := c.Next
+		c.Unlink()
+		c = next
+	}
+
+	var list string // Tag of the open list, if any.
+	for _, segment := range segments {
+		trimSegment(segment)
+		if len(segment) == 0 {
+			continue
+		}
+		tag := listMarker(segment[0])
+		if tag != list {
+			if list != "" {
+				appendHTML(cell, "</"+list+">")
+			}
+			if tag != "" {
+				appendHTML(cell, "<"+tag+">")
+			}
+			list = tag
+		}
+		if tag != "" {
+			appendHTML(cell, "<li>")
+		} else {
+			appendHTML(cell, "<p>")
+		}
+		for _, n := range segment {
+			cell.AppendChild(n)
+		}
+		if tag != "" {
+			appendHTML(cell, "</li>")
+		} else {
+			appendHTML(cell, "</p>")
+		}
+	}
+	if list != "" {
+		appendHTML(cell, "</"+list+">")
+	}
+}
+
+// trimSegment trims the leading and trailing whitespace of a cell segment.
+func trimSegment(segment []*bf.Node) {
+	if len(segment) == 0 {
+		return
+	}
+	if first := segment[0]; first.Type == bf.Text {
+		first.Literal = bytes.TrimLeft(first.Literal, " \t")
+	}
+	if last := segment[len(segment)-1]; last.Type == bf.Text {
+		last.Literal = bytes.TrimRight(last.Literal, " \t")
+	}
+}
+
+// listMarker removes a list marker from the start of the text node n,
+// and returns the tag of the list it starts, or "" if there is none.
+func listMarker(n *bf.Node) string {
+	if n.Type != bf.Text {
+		return ""
+	}
+	if m := bulletItemRE.Find(n.Literal); m != nil {
+		n.Literal = n.Literal[len(m):]
+		return "ul"
+	}
+	if m := orderedRE.Find(n.Literal); m != nil {
+		n.Literal = n.Literal[len(m):]
+		return "ol"
+	}
+	return ""
+}
+
+// appendHTML appends raw inline HTML to node.
+func appendHTML(node *bf.Node, html string) {
+	n := bf.NewNode(bf.HTMLSpan)
+	n.Literal = []byte(html)
+	node.AppendChild(n)
+}
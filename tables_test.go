@@ -0,0 +1,19 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestRichTableCells(t *testing.T) {
+	text := "| Task | Owner |\n|---|---|\n| Steps:<br>- one<br>- *two* | me |\n"
+	want := "<table>\n<thead>\n<tr>\n<th>Task</th>\n<th>Owner</th>\n</tr>\n</thead>\n\n<tbody>\n<tr>\n" +
+		`<td><p>Steps:</p><ul><li>one</li><li><em>two</em></li></ul></td>` + "\n" +
+		`<td>me</td>` + "\n</tr>\n</tbody>\n</table>\n"
+
+	opts := github_flavored_markdown.Options{RichTableCells: true}
+	if got := string(github_flavored_markdown.MarkdownOptions([]byte(text), opts)); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package github_flavored_markdown
+
+import (
+	"regexp"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// filteredTagRE matches the start of the tags that the GFM tagfilter
+// extension disallows in raw HTML.
+var filteredTagRE = regexp.MustCompile(`(?i)</?(title|textarea|style|xmp|iframe|noembed|noframes|script|plaintext)([\t\n\f\r />]|$)`)
+
+// tagFilter applies the GFM tagfilter extension to the raw HTML in doc,
+// escaping the "<" of disallowed tags so that they render as text.
+func tagFilter(doc *bf.Node) {
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if entering && (node.Type == bf.HTMLBlock || node.Type == bf.HTMLSpan) {
+			node.Literal = filterTags(node.Literal)
+		}
+		return bf.GoToNext
+	})
+}
+
+// filterTags escapes the disallowed tags in the raw HTML b.
+func filterTags(b []byte) []byte {
+	return filteredTagRE.ReplaceAllFunc(b, func(tag []byte) []byte {
+		return append([]byte("&lt;"), tag[1:]...)
+	})
+}
@@ -0,0 +1,34 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestWithTagFilter(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "Hello <script>alert();</script> <STYLE>p{}</STYLE> world.",
+			want: `<p>Hello &lt;script&gt;alert();&lt;/script&gt; &lt;STYLE&gt;p{}&lt;/STYLE&gt; world.</p>` + "\n",
+		},
+		{
+			text: "<div>\n<textarea rows=\"2\">x</textarea>\n</div>\n",
+			want: `<div>` + "\n" + `&lt;textarea rows=&#34;2&#34;&gt;x&lt;/textarea&gt;` + "\n" + `</div>` + "\n",
+		},
+		{
+			// Other tags, and tags that only start with a filtered name, are kept.
+			text: "<span>a</span> <titles>b</titles>",
+			want: `<p><span>a</span> b</p>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithTagFilter())); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// Checkboxes of task list items.
+const (
+	taskUnchecked = `<input type="checkbox" disabled="">`
+	taskChecked   = `<input type="checkbox" checked="" disabled="">`
+)
+
+// taskRE matches the "[ ]" or "[x]" marker at the start of a task list item.
+var taskRE = regexp.MustCompile(`^\[([ xX])\](?:[ \t]|$)`)
+
+// taskLists replaces the markers of task list items in doc with checkboxes.
+func taskLists(doc *bf.Node) {
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering || node.Type != bf.Item {
+			return bf.GoToNext
+		}
+		para := node.FirstChild
+		if para == nil || para.Type != bf.Paragraph || para.FirstChild == nil || para.FirstChild.Type != bf.Text {
+			return bf.GoToNext
+		}
+		text := para.FirstChild
+		m := taskRE.FindSubmatch(text.Literal)
+		if m == nil {
+			return bf.GoToNext
+		}
+		checkbox := taskUnchecked
+		if m[1][0] != ' ' {
+			checkbox = taskChecked
+		}
+		text.Literal = text.Literal[len("[ ]"):]
+		text.InsertBefore(newHTMLSpan(checkbox))
+		return bf.GoToNext
+	})
+}
+
+// TaskItem is a task list item, as returned by TaskItems.
+type TaskItem struct {
+	Checked bool
+	Text    string // Plain text of the item's first paragraph.
+
+	// Start and End are the byte offsets of the "[ ]" or "[x]" marker in
+	// the source text, or -1 if it could not be found.
+	Start, End int
+}
+
+// TaskItems returns the task list items in GitHub Flavored Markdown text,
+// in document order.
+func TaskItems(text []byte, opts ...Option) []TaskItem {
+	var items []TaskItem
+	doc := Parse(text, opts...)
+	doc.Node.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering || node.Type != bf.Item {
+			return bf.GoToNext
+		}
+		para := node.FirstChild
+		if para == nil || para.Type != bf.Paragraph || para.FirstChild == nil || para.FirstChild.Type != bf.HTMLSpan {
+			return bf.GoToNext
+		}
+		switch string(para.FirstChild.Literal) {
+		case taskUnchecked:
+			items = append(items, TaskItem{Checked: false, Text: inlineText(para)})
+		case taskChecked:
+			items = append(items, TaskItem{Checked: true, Text: inlineText(para)})
+		}
+		return bf.GoToNext
+	})
+
+	spans := taskMarkerSpans(text)
+	for i := range items {
+		items[i].Start, items[i].End = -1, -1
+		if len(spans) == len(items) {
+			items[i].Start, items[i].End = spans[i][0], spans[i][1]
+		}
+	}
+	return items
+}
+
+// ToggleTask returns a copy of GitHub Flavored Markdown text with the task
+// list item with the given index, as in the result of TaskItems, checked if
+// it isn't, and unchecked otherwise. If there is no such item, or its marker
+// could not be found, it returns text unchanged.
+func ToggleTask(text []byte, index int, opts ...Option) []byte {
+	items := TaskItems(text, opts...)
+	if index < 0 || index >= len(items) || items[index].Start < 0 {
+		return text
+	}
+	out := append([]byte(nil), text...)
+	if items[index].Checked {
+		out[items[index].Start+1] = ' '
+	} else {
+		out[items[index].Start+1] = 'x'
+	}
+	return out
+}
+
+// taskMarkerRE matches a list item line that starts with a task marker.
+var taskMarkerRE = regexp.MustCompile(`^[ \t]*(?:>[ \t]*)*(?:[-+*]|[0-9]{1,9}[.)])[ \t]+(\[[ xX]\])(?:[ \t]|$)`)
+
+// taskMarkerSpans returns the byte offsets of the task markers in text,
+// outside of fenced code blocks.
+func taskMarkerSpans(text []byte) [][2]int {
+	var spans [][2]int
+	var fence []byte // Opening fence of the current fenced code block, if any.
+	for start := 0; start < len(text); {
+		end := bytes.IndexByte(text[start:], '\n') + 1
+		if end == 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		line := bytes.TrimRight(text[start:end], "\r\n")
+		trimmed := bytes.TrimLeft(line, " \t")
+
+		switch {
+		case fence != nil:
+			if bytes.HasPrefix(trimmed, fence) && len(bytes.TrimLeft(trimmed, string(fence[:1])+" \t")) == 0 {
+				fence = nil
+			}
+		case codeFenceRE.Match(trimmed):
+			fence = codeFenceRE.Find(trimmed)
+		default:
+			if m := taskMarkerRE.FindSubmatchIndex(line); m != nil {
+				spans = append(spans, [2]int{start + m[2], start + m[3]})
+			}
+		}
+		start = end
+	}
+	return spans
+}
@@ -0,0 +1,50 @@
+package github_flavored_markdown_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestTaskLists(t *testing.T) {
+	text := "1. [x] Done *now*\n2. [ ] Todo\n   - [X] Nested\n3. Not a [ ] task\n"
+	want := `<ol>
+<li><input type="checkbox" checked="" disabled=""> Done <em>now</em></li>
+<li><input type="checkbox" disabled=""> Todo
+
+<ul>
+<li><input type="checkbox" checked="" disabled=""> Nested</li>
+</ul></li>
+<li>Not a [ ] task</li>
+</ol>
+`
+	if got := string(github_flavored_markdown.Markdown([]byte(text))); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
+
+func TestTaskItems(t *testing.T) {
+	text := "```\n- [ ] in code\n```\n\n- [ ] Write *tests*\n- [x] Fix bug\n  - [ ] Nested\n- Plain\n"
+	want := []github_flavored_markdown.TaskItem{
+		{Checked: false, Text: "Write tests", Start: 25, End: 28},
+		{Checked: true, Text: "Fix bug", Start: 45, End: 48},
+		{Checked: false, Text: "Nested", Start: 61, End: 64},
+	}
+	got := github_flavored_markdown.TaskItems([]byte(text))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %+v\nwant %+v", got, want)
+	}
+
+	toggled := github_flavored_markdown.ToggleTask([]byte(text), 0)
+	toggled = github_flavored_markdown.ToggleTask(toggled, 1)
+	wantText := "```\n- [ ] in code\n```\n\n- [x] Write *tests*\n- [ ] Fix bug\n  - [ ] Nested\n- Plain\n"
+	if string(toggled) != wantText {
+		t.Errorf("\ngot %q\nwant %q", toggled, wantText)
+	}
+
+	// Out of range.
+	if got := github_flavored_markdown.ToggleTask([]byte(text), 3); string(got) != text {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package github_flavored_markdown
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// HTML renders text like Markdown, and returns the output as template.HTML,
+// which html/template inserts into pages as is rather than escaping it.
+//
+// Sanitized output is safe to insert as the content of an element in the
+// body of a page, which is where html/template expects template.HTML: the
+// policy removes elements such as <script> and <style>, event handler
+// attributes, and URLs with schemes other than those allowed. It is not
+// safe in other contexts, such as attribute values and <script> elements,
+// where html/template strips its tags or escapes it anyway.
+// With SkipSanitization, or with a Policy that allows more, the output is
+// only as safe as text, and HTML must only be used with trusted text.
+func HTML(text []byte, opts ...Option) template.HTML {
+	return template.HTML(Markdown(text, opts...))
+}
+
+// FuncMap returns functions for html/template templates that render
+// Markdown with opts:
+//
+//	markdown	renders a string or []byte like HTML
+//
+// For example, with the functions added to a template with Funcs,
+// {{markdown .Body}} inserts Body rendered as HTML. The options are
+// processed once, like with NewRenderer. The same safety considerations as
+// for HTML apply.
+func FuncMap(opts ...Option) template.FuncMap {
+	r := NewRenderer(opts...)
+	return template.FuncMap{
+		"markdown": func(text interface{}) (template.HTML, error) {
+			switch text := text.(type) {
+			case string:
+				return template.HTML(r.Render([]byte(text))), nil
+			case []byte:
+				return template.HTML(r.Render(text)), nil
+			default:
+				return "", fmt.Errorf("github_flavored_markdown: markdown of %T, want string or []byte", text)
+			}
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package github_flavored_markdown_test
+
+import (
+	"html/template"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func ExampleFuncMap() {
+	t := template.Must(template.New("post").Funcs(github_flavored_markdown.FuncMap()).Parse(
+		`<h1>{{.Title}}</h1>{{markdown .Body}}`))
+	t.Execute(os.Stdout, struct{ Title, Body string }{
+		Title: "Hello <World>",
+		Body:  "Some **bold** text <script>alert();</script>",
+	})
+
+	// Output:
+	// <h1>Hello &lt;World&gt;</h1><p>Some <strong>bold</strong> text </p>
+}
+
+func TestFuncMap(t *testing.T) {
+	tmpl := template.Must(template.New("").Funcs(github_flavored_markdown.FuncMap(github_flavored_markdown.WithEmoji())).Parse(
+		`{{markdown .}}<a title="{{markdown .}}">`))
+
+	tests := []struct {
+		data interface{}
+		want string
+	}{
+		{
+			data: ":smile: *a*",
+			want: "<p>😄 <em>a</em></p>\n<a title=\"😄 a\n\">",
+		},
+		{
+			data: []byte(":smile:"),
+			want: "<p>😄</p>\n<a title=\"😄\n\">",
+		},
+	}
+	for _, test := range tests {
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, test.data); err != nil {
+			t.Errorf("%q: %v", test.data, err)
+			continue
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+
+	if err := tmpl.Execute(new(strings.Builder), 42); err == nil {
+		t.Error("no error for an int")
+	}
+}
+
+func TestHTML(t *testing.T) {
+	got := github_flavored_markdown.HTML([]byte("<b onclick=\"alert()\">a</b>"))
+	if want := template.HTML("<p><b>a</b></p>\n"); got != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,274 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// Text renders GitHub Flavored Markdown text as plain text, without any
+// formatting, for uses such as search indexing and email. Blocks are
+// separated by blank lines, list items are marked with "-" or their
+// number, table cells are separated by tabs, and images are replaced
+// with their alt text.
+func Text(text []byte, opts ...Option) []byte {
+	return textRenderer{}.render(Parse(text, opts...))
+}
+
+// Terminal renders GitHub Flavored Markdown text like Text, but styled with
+// ANSI escape sequences for display in terminals. Headings and emphasis are
+// bold or italic, links are underlined and followed by their URLs, code is
+// highlighted, and block quotes are marked with a bar.
+func Terminal(text []byte, opts ...Option) []byte {
+	doc := Parse(text, opts...)
+	return textRenderer{ansi: newRenderer(doc.opts)}.render(doc)
+}
+
+// textRenderer renders documents as plain text, or as text styled with ANSI
+// escape sequences if ansi is set. The renderer is used to highlight code.
+type textRenderer struct {
+	ansi *renderer
+}
+
+func (t textRenderer) render(doc *Document) []byte {
+	out := strings.TrimRight(strings.TrimLeft(t.block(doc.Node), "\n"), " \n")
+	if out == "" {
+		return nil
+	}
+	return []byte(out + "\n")
+}
+
+// sgr returns the ANSI escape sequence that sets the graphic rendition
+// params, or "" for plain text.
+func (t textRenderer) sgr(params string) string {
+	if t.ansi == nil {
+		return ""
+	}
+	return "\x1b[" + params + "m"
+}
+
+// block returns the text of the block node.
+func (t textRenderer) block(node *bf.Node) string {
+	switch node.Type {
+	case bf.Document:
+		return t.childBlocks(node, "\n\n")
+	case bf.BlockQuote:
+		text := t.childBlocks(node, "\n\n")
+		if t.ansi != nil {
+			text = indentText(t.sgr("90")+"│"+t.sgr("39")+" ", "  ", text)
+		}
+		return text
+	case bf.Heading:
+		if t.ansi != nil {
+			return t.sgr("1;34") + strings.Repeat("#", node.HeadingData.Level) + " " + t.inline(node) + t.sgr("0")
+		}
+	case bf.List:
+		var items []string
+		number := 1 // blackfriday doesn't keep the start number.
+		for item := node.FirstChild; item != nil; item = item.Next {
+			marker := "- "
+			if node.ListData.ListFlags&bf.ListTypeOrdered != 0 {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			items = append(items, indentText(marker, strings.Repeat(" ", len(marker)), t.childBlocks(item, "\n")))
+		}
+		return strings.Join(items, "\n")
+	case bf.CodeBlock:
+		return t.code(node)
+	case bf.HTMLBlock:
+		return strings.TrimSpace(htmlText(node.Literal))
+	case bf.Table:
+		var rows []string
+		node.Walk(func(n *bf.Node, entering bool) bf.WalkStatus {
+			if entering && n.Type == bf.TableRow {
+				var cells []string
+				for cell := n.FirstChild; cell != nil; cell = cell.Next {
+					text := t.inline(cell)
+					if cell.TableCellData.IsHeader {
+						text = t.sgr("1") + text + t.sgr("22")
+					}
+					cells = append(cells, text)
+				}
+				rows = append(rows, strings.Join(cells, "\t"))
+				return bf.SkipChildren
+			}
+			return bf.GoToNext
+		})
+		return strings.Join(rows, "\n")
+	case bf.HorizontalRule:
+		if t.ansi != nil {
+			return t.sgr("90") + strings.Repeat("─", 40) + t.sgr("39")
+		}
+		return ""
+	}
+	return t.inline(node)
+}
+
+// childBlocks returns the text of the children of node, separated by sep.
+func (t textRenderer) childBlocks(node *bf.Node, sep string) string {
+	var blocks []string
+	for child := node.FirstChild; child != nil; child = child.Next {
+		if text := t.block(child); text != "" {
+			blocks = append(blocks, text)
+		}
+	}
+	return strings.Join(blocks, sep)
+}
+
+// indentText prefixes the first line of text with first, and the others
+// with rest.
+func indentText(first, rest, text string) string {
+	return first + strings.Replace(text, "\n", "\n"+rest, -1)
+}
+
+// code returns the text of a code block. For terminals, it is highlighted
+// and indented.
+func (t textRenderer) code(node *bf.Node) string {
+	code := strings.TrimSuffix(string(node.Literal), "\n")
+	if t.ansi == nil {
+		return code
+	}
+	lang := findLang(node.Info)
+	if len(lang) == 0 && node.IsFenced {
+		lang = []byte(t.ansi.opts.DefaultCodeLang)
+	}
+	if len(lang) > 0 {
+		if highlighted, ok := t.ansi.highlight([]byte(code), canonicalLang(string(lang), t.ansi.opts)); ok {
+			code = ansiHighlight(highlighted)
+		}
+	}
+	return indentText("    ", "    ", code)
+}
+
+// inline returns the text of the inline content of node.
+func (t textRenderer) inline(node *bf.Node) string {
+	var buf bytes.Buffer
+	node.Walk(func(n *bf.Node, entering bool) bf.WalkStatus {
+		switch n.Type {
+		case bf.Text:
+			buf.WriteString(html.UnescapeString(string(n.Literal)))
+		case bf.Code:
+			buf.WriteString(t.sgr("36") + string(n.Literal) + t.sgr("39"))
+		case bf.HTMLSpan:
+			buf.WriteString(htmlText(n.Literal))
+		case bf.Softbreak, bf.Hardbreak:
+			buf.WriteString("\n")
+		case bf.Strong:
+			buf.WriteString(t.style(entering, "1", "22"))
+		case bf.Emph:
+			buf.WriteString(t.style(entering, "3", "23"))
+		case bf.Del:
+			buf.WriteString(t.style(entering, "9", "29"))
+		case bf.Link:
+			buf.WriteString(t.style(entering, "4", "24"))
+			if !entering && t.ansi != nil {
+				if dest := string(n.LinkData.Destination); dest != strings.TrimSpace(inlineText(n)) {
+					buf.WriteString(" " + t.sgr("90") + "(" + dest + ")" + t.sgr("39"))
+				}
+			}
+		}
+		return bf.GoToNext
+	})
+	return strings.TrimSpace(buf.String())
+}
+
+// style returns the sequence that starts a style when entering a node,
+// and the one that ends it when leaving.
+func (t textRenderer) style(entering bool, start, end string) string {
+	if entering {
+		return t.sgr(start)
+	}
+	return t.sgr(end)
+}
+
+// inlineText returns the plain text of the inline content of node.
+func inlineText(node *bf.Node) string {
+	return textRenderer{}.inline(node)
+}
+
+// htmlText returns the text content of an HTML fragment, using the alt
+// text of images and skipping scripts and styles.
+func htmlText(fragment []byte) string {
+	var buf bytes.Buffer
+	var skip bool // Inside an element whose text isn't content.
+	z := html.NewTokenizer(bytes.NewReader(fragment))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return buf.String()
+		case html.TextToken:
+			if !skip {
+				buf.WriteString(html.UnescapeString(string(z.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			switch t.DataAtom {
+			case atom.Script, atom.Style:
+				skip = true
+			case atom.Img:
+				for _, a := range t.Attr {
+					if a.Key == "alt" {
+						buf.WriteString(a.Val)
+					}
+				}
+			}
+		case html.EndTagToken:
+			skip = false
+		}
+	}
+}
+
+// ansiColors maps the classes of highlighted code to ANSI foreground colors.
+var ansiColors = map[string]string{
+	gfmHTMLConfig.Keyword:       "35",
+	gfmHTMLConfig.String:        "32",
+	gfmHTMLConfig.Comment:       "90",
+	gfmHTMLConfig.Decimal:       "36",
+	gfmHTMLConfig.Literal:       "36",
+	gfmHTMLConfig.HTMLTag:       "34",
+	gfmHTMLConfig.Tag:           "34",
+	gfmHTMLConfig.HTMLAttrName:  "33",
+	gfmHTMLConfig.HTMLAttrValue: "32",
+	"gd":                        "31",
+	"gi":                        "32",
+}
+
+// ansiHighlight converts highlighted code from HTML spans with classes to
+// text with ANSI colors.
+func ansiHighlight(highlighted []byte) string {
+	var buf bytes.Buffer
+	colors := []string{"39"} // Stack of the colors of open spans.
+	z := html.NewTokenizer(bytes.NewReader(highlighted))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return buf.String()
+		case html.TextToken:
+			buf.WriteString(html.UnescapeString(string(z.Text())))
+		case html.StartTagToken:
+			color := colors[len(colors)-1]
+			for _, a := range z.Token().Attr {
+				if a.Key != "class" {
+					continue
+				}
+				for _, class := range strings.Fields(a.Val) {
+					if c, ok := ansiColors[class]; ok {
+						color = c
+					}
+				}
+			}
+			colors = append(colors, color)
+			buf.WriteString("\x1b[" + color + "m")
+		case html.EndTagToken:
+			if len(colors) > 1 {
+				colors = colors[:len(colors)-1]
+			}
+			buf.WriteString("\x1b[" + colors[len(colors)-1] + "m")
+		}
+	}
+}
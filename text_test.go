@@ -0,0 +1,80 @@
+package github_flavored_markdown_test
+
+import (
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		text string
+		opts []github_flavored_markdown.Option
+		want string
+	}{
+		{
+			text: "# Title\n\nSome **bold** and `code` with a [link](https://example.com) &amp; an ![image](a.png).\n",
+			want: "Title\n\nSome bold and code with a link & an image.\n",
+		},
+		{
+			text: "- one\n- two\n  1. nested\n  2. list\n\n> quoted\n",
+			want: "- one\n- two\n  1. nested\n  2. list\n\nquoted\n",
+		},
+		{
+			text: "| a | b |\n|---|---|\n| 1 | *2* |\n",
+			want: "a\tb\n1\t2\n",
+		},
+		{
+			text: "```go\nfmt.Println(\"hi\")\n```\n\n---\n\n<div>raw <b>html</b><script>x()</script></div>\n",
+			want: "fmt.Println(\"hi\")\n\nraw html\n",
+		},
+		{
+			text: "Ship it :shipit:, @alice.\n",
+			opts: []github_flavored_markdown.Option{
+				github_flavored_markdown.WithEmoji(),
+				github_flavored_markdown.WithMentions(func(name string) (string, bool) { return "https://github.com/" + name, true }),
+			},
+			want: "Ship it :shipit:, @alice.\n",
+		},
+		{
+			text: "",
+			want: "",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Text([]byte(test.text), test.opts...)); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "## Title\n\nSome **bold**, *italic* and `code`.\n",
+			want: "\x1b[1;34m## Title\x1b[0m\n\nSome \x1b[1mbold\x1b[22m, \x1b[3mitalic\x1b[23m and \x1b[36mcode\x1b[39m.\n",
+		},
+		{
+			text: "See [the docs](https://example.com) or https://example.org.\n",
+			want: "See \x1b[4mthe docs\x1b[24m \x1b[90m(https://example.com)\x1b[39m or \x1b[4mhttps://example.org\x1b[24m.\n",
+		},
+		{
+			text: "> quoted\n> text\n",
+			want: "\x1b[90m│\x1b[39m quoted\n  text\n",
+		},
+		{
+			text: "```cmake\nproject(x)\n```\n",
+			want: "    \x1b[35mproject\x1b[39m(x)\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(github_flavored_markdown.Terminal([]byte(test.text))); got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
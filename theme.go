@@ -0,0 +1,158 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Theme is a color scheme for highlighted code blocks. Each field is a CSS
+// color, or empty to leave the elements it applies to styled as by CSS.
+// The stylesheet of a theme, returned by its CSS method, must be included
+// after that of CSS, whose colors it overrides.
+type Theme struct {
+	Background  string // Background of code blocks.
+	Text        string // Text that isn't highlighted.
+	Comment     string
+	Keyword     string
+	String      string
+	Number      string
+	Literal     string // Literals other than strings and numbers, such as characters.
+	Name        string // Identifiers and types.
+	Punctuation string // Punctuation and operators.
+	Tag         string // HTML and XML tags.
+	AttrName    string // HTML and XML attribute names.
+	AttrValue   string // HTML and XML attribute values.
+
+	// Deleted and Inserted are the backgrounds of deleted and inserted
+	// lines of diffs, and DeletedWord and InsertedWord are those of the
+	// changed words within them.
+	Deleted, Inserted         string
+	DeletedWord, InsertedWord string
+
+	LineNumber      string // Line numbers of Options.LineNumbers.
+	HighlightedLine string // Background of lines highlighted with {1,3-5}.
+}
+
+// The built-in themes, which ThemeCSS returns the stylesheets of by name.
+// They can be copied and modified to define other themes.
+var (
+	// LightTheme is a light theme in the colors of the gfmstyle CSS.
+	LightTheme = Theme{
+		Background:      "#f7f7f7",
+		Text:            "#333",
+		Comment:         "#998",
+		Keyword:         "#000",
+		String:          "#df5000",
+		Number:          "#945277",
+		Literal:         "#008080",
+		Name:            "#333",
+		Punctuation:     "#333",
+		Tag:             "#000080",
+		AttrName:        "#008080",
+		AttrValue:       "#df5000",
+		Deleted:         "#fdd",
+		Inserted:        "#dfd",
+		DeletedWord:     "#faa",
+		InsertedWord:    "#afa",
+		LineNumber:      "#aaa",
+		HighlightedLine: "#fffbdd",
+	}
+
+	// DarkTheme is a dark theme in the colors of GitHub's dark mode.
+	DarkTheme = Theme{
+		Background:      "#161b22",
+		Text:            "#e6edf3",
+		Comment:         "#8b949e",
+		Keyword:         "#ff7b72",
+		String:          "#a5d6ff",
+		Number:          "#79c0ff",
+		Literal:         "#79c0ff",
+		Name:            "#e6edf3",
+		Punctuation:     "#e6edf3",
+		Tag:             "#7ee787",
+		AttrName:        "#79c0ff",
+		AttrValue:       "#a5d6ff",
+		Deleted:         "#490202",
+		Inserted:        "#04260f",
+		DeletedWord:     "#8e1519",
+		InsertedWord:    "#196c2e",
+		LineNumber:      "#6e7681",
+		HighlightedLine: "#2f2a16",
+	}
+
+	// GitHubTheme is a light theme in the colors of GitHub.
+	GitHubTheme = Theme{
+		Background:      "#f6f8fa",
+		Text:            "#1f2328",
+		Comment:         "#6e7781",
+		Keyword:         "#cf222e",
+		String:          "#0a3069",
+		Number:          "#0550ae",
+		Literal:         "#0550ae",
+		Name:            "#1f2328",
+		Punctuation:     "#1f2328",
+		Tag:             "#116329",
+		AttrName:        "#0550ae",
+		AttrValue:       "#0a3069",
+		Deleted:         "#ffebe9",
+		Inserted:        "#dafbe1",
+		DeletedWord:     "#ffcecb",
+		InsertedWord:    "#aceebb",
+		LineNumber:      "#6e7781",
+		HighlightedLine: "#fff8c5",
+	}
+)
+
+// themes are the built-in themes by name.
+var themes = map[string]*Theme{
+	"light":  &LightTheme,
+	"dark":   &DarkTheme,
+	"github": &GitHubTheme,
+}
+
+// ThemeCSS returns the stylesheet of the built-in theme with the given
+// name, "light", "dark" or "github", or nil if there is no such theme.
+// See Theme.CSS.
+func ThemeCSS(theme string) []byte {
+	t, ok := themes[theme]
+	if !ok {
+		return nil
+	}
+	return t.CSS()
+}
+
+// CSS returns the stylesheet of t, which styles the classes of the
+// highlighted code that is rendered.
+func (t Theme) CSS() []byte {
+	var buf bytes.Buffer
+	rule := func(selector, property, value string) {
+		if value != "" {
+			fmt.Fprintf(&buf, "%s{%s:%s}\n", selector, property, value)
+		}
+	}
+	rule(".highlight,.markdown-body .highlight pre", "background-color", t.Background)
+	rule(".markdown-body .highlight pre,.highlight .gd,.highlight .gi,.highlight .gd .x,.highlight .gi .x", "color", t.Text)
+	for _, c := range []struct{ class, color string }{
+		// The classes are those of gfmHTMLConfig, which all highlighters use.
+		{gfmHTMLConfig.Comment, t.Comment},
+		{gfmHTMLConfig.Keyword, t.Keyword},
+		{gfmHTMLConfig.String, t.String},
+		{gfmHTMLConfig.Decimal, t.Number},
+		{gfmHTMLConfig.Literal, t.Literal},
+		{gfmHTMLConfig.Plaintext, t.Name}, // Also Type.
+		{gfmHTMLConfig.Punctuation, t.Punctuation},
+		{gfmHTMLConfig.Tag, t.Tag},
+		{gfmHTMLConfig.HTMLTag, t.Tag},
+		{gfmHTMLConfig.HTMLAttrName, t.AttrName},
+		{gfmHTMLConfig.HTMLAttrValue, t.AttrValue},
+	} {
+		rule(".highlight ."+c.class, "color", c.color)
+	}
+	rule(".highlight .gd", "background-color", t.Deleted)
+	rule(".highlight .gi", "background-color", t.Inserted)
+	rule(".highlight .gd .x", "background-color", t.DeletedWord)
+	rule(".highlight .gi .x", "background-color", t.InsertedWord)
+	rule(".markdown-body .ln", "color", t.LineNumber)
+	rule(".markdown-body .hl", "background-color", t.HighlightedLine)
+	return buf.Bytes()
+}
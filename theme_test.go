@@ -0,0 +1,48 @@
+package github_flavored_markdown_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestThemeCSS(t *testing.T) {
+	// Highlighted code, whose classes each theme must have colors for.
+	out := github_flavored_markdown.Markdown([]byte("```python\n# Comment.\ndef f(): return 'a' + str(1.5) + None\n```\n\n" +
+		"```html\n<a href=\"b\">c</a>\n```\n\n```diff\n-a b\n+a c\n```\n"))
+	classes := make(map[string]bool)
+	for _, m := range regexp.MustCompile(`<span class="([^" ]*)`).FindAllSubmatch(out, -1) {
+		classes[string(m[1])] = true
+	}
+	if len(classes) < 5 {
+		t.Fatalf("only %d classes in the output:\n%s", len(classes), out)
+	}
+
+	for _, name := range []string{"light", "dark", "github"} {
+		css := github_flavored_markdown.ThemeCSS(name)
+		for class := range classes {
+			if !regexp.MustCompile(`\.highlight (\.\w+ )*\.` + regexp.QuoteMeta(class) + `\b[^{]*{(color|background-color):`).Match(css) {
+				t.Errorf("%s: no color for class %q:\n%s", name, class, css)
+			}
+		}
+	}
+	if css := github_flavored_markdown.ThemeCSS("nosuchtheme"); css != nil {
+		t.Errorf("got %q for an unknown theme, want nil", css)
+	}
+}
+
+func TestThemeCustom(t *testing.T) {
+	theme := github_flavored_markdown.Theme{Keyword: "#f00", HighlightedLine: "yellow"}
+	want := ".highlight .k{color:#f00}\n.markdown-body .hl{background-color:yellow}\n"
+	if got := theme.CSS(); string(got) != want {
+		t.Errorf("\ngot %q\nwant %q", got, want)
+	}
+
+	dark := github_flavored_markdown.DarkTheme
+	dark.Comment = "#123456"
+	if css := dark.CSS(); !bytes.Contains(css, []byte(".highlight .c{color:#123456}")) {
+		t.Errorf("modified theme doesn't have the comment color:\n%s", css)
+	}
+}
@@ -0,0 +1,137 @@
+package github_flavored_markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+
+	bf "gopkg.in/russross/blackfriday.v2"
+)
+
+// HeadingEntry is a heading in a document's table of contents.
+type HeadingEntry struct {
+	Level  int    // Heading level, 1 to 6.
+	Title  string // Plain text title.
+	Anchor string // Anchor name, without "#".
+}
+
+// TOC returns the headings of text, in document order, with the same anchor
+// names as in the output of Markdown with the same options.
+func TOC(text []byte, opts ...Option) []HeadingEntry {
+	o := newOptions(opts)
+	doc, _ := newRenderer(o).parse(text)
+	return headings(doc, o)
+}
+
+// Title returns the plain text title of the first level 1 heading in text,
+// flattened like heading titles are for anchor names. It reports false if
+// there is no such heading.
+func Title(text []byte, opts ...Option) (string, bool) {
+	for _, e := range TOC(text, opts...) {
+		if e.Level == 1 {
+			return e.Title, true
+		}
+	}
+	return "", false
+}
+
+// headings returns the headings of doc, naming anchors like the renderer does.
+func headings(doc *bf.Node, opts Options) []HeadingEntry {
+	anchors := newAnchorNamer(opts)
+	var entries []HeadingEntry
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if entering && node.Type == bf.Heading {
+			title := headingText(node)
+			entries = append(entries, HeadingEntry{
+				Level:  node.HeadingData.Level,
+				Title:  title,
+				Anchor: anchors.name(title),
+			})
+			return bf.SkipChildren
+		}
+		return bf.GoToNext
+	})
+	return entries
+}
+
+// writeTOC writes entries as a <nav class="toc"> element with nested lists
+// following the heading levels.
+func writeTOC(w io.Writer, entries []HeadingEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	io.WriteString(w, "<nav class=\"toc\">\n")
+	var levels []int // Levels of the open lists.
+	for i, e := range entries {
+		switch {
+		case len(levels) == 0 || e.Level > levels[len(levels)-1]:
+			if i > 0 {
+				io.WriteString(w, "\n")
+			}
+			io.WriteString(w, "<ul>\n")
+			levels = append(levels, e.Level)
+		default:
+			io.WriteString(w, "</li>\n")
+			for len(levels) > 1 && e.Level < levels[len(levels)-1] {
+				io.WriteString(w, "</ul>\n</li>\n")
+				levels = levels[:len(levels)-1]
+			}
+		}
+		fmt.Fprintf(w, `<li><a href="%s">%s</a>`, anchorHref(e.Anchor), template.HTMLEscapeString(e.Title))
+	}
+	io.WriteString(w, "</li>\n")
+	for range levels[1:] {
+		io.WriteString(w, "</ul>\n</li>\n")
+	}
+	io.WriteString(w, "</ul>\n</nav>\n")
+}
+
+// DefaultTOCMarker is the conventional table of contents marker.
+const DefaultTOCMarker = "[TOC]"
+
+// replaceTOCMarkers replaces each paragraph or HTML block of doc that consists
+// of just marker, ignoring case and surrounding space, with a table of contents.
+func replaceTOCMarkers(doc *bf.Node, marker string, entries []HeadingEntry) {
+	var markers []*bf.Node
+	doc.Walk(func(node *bf.Node, entering bool) bf.WalkStatus {
+		if !entering {
+			return bf.GoToNext
+		}
+		switch node.Type {
+		case bf.Paragraph:
+			if isTOCMarker(node.FirstChild, marker) && node.FirstChild == node.LastChild {
+				markers = append(markers, node)
+			}
+			return bf.SkipChildren
+		case bf.HTMLBlock:
+			if isTOCMarker(node, marker) {
+				markers = append(markers, node)
+			}
+		}
+		return bf.GoToNext
+	})
+	if len(markers) == 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	writeTOC(&buf, entries)
+	for _, node := range markers {
+		toc := bf.NewNode(bf.HTMLBlock)
+		toc.Literal = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		node.InsertBefore(toc)
+		node.Unlink()
+	}
+}
+
+func isTOCMarker(node *bf.Node, marker string) bool {
+	if node == nil {
+		return false
+	}
+	switch node.Type {
+	case bf.Text, bf.HTMLSpan, bf.HTMLBlock:
+		return bytes.EqualFold(bytes.TrimSpace(node.Literal), []byte(marker))
+	}
+	return false
+}
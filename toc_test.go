@@ -0,0 +1,110 @@
+package github_flavored_markdown_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/github_flavored_markdown"
+)
+
+func TestTOC(t *testing.T) {
+	text := "# Title\n\n## Install\n\n### From *source*\n\n## Usage\n\n## Usage\n\n# Other\n"
+
+	want := []github_flavored_markdown.HeadingEntry{
+		{Level: 1, Title: "Title", Anchor: "title"},
+		{Level: 2, Title: "Install", Anchor: "install"},
+		{Level: 3, Title: "From source", Anchor: "from-source"},
+		{Level: 2, Title: "Usage", Anchor: "usage"},
+		{Level: 2, Title: "Usage", Anchor: "usage-1"},
+		{Level: 1, Title: "Other", Anchor: "other"},
+	}
+	got := github_flavored_markdown.TOC([]byte(text))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %+v\nwant %+v", got, want)
+	}
+
+	// Every anchor matches a heading in the output.
+	out := string(github_flavored_markdown.Markdown([]byte(text)))
+	for _, e := range got {
+		if !strings.Contains(out, `<a name="`+e.Anchor+`"`) {
+			t.Errorf("anchor %q not in output", e.Anchor)
+		}
+	}
+}
+
+func TestWithTOC(t *testing.T) {
+	text := "## A\n\n### B & C\n\n# D\n"
+	want := `<nav class="toc">
+<ul>
+<li><a href="#a" rel="nofollow">A</a>
+<ul>
+<li><a href="#b-c" rel="nofollow">B &amp; C</a></li>
+</ul>
+</li>
+<li><a href="#d" rel="nofollow">D</a></li>
+</ul>
+</nav>
+`
+	got := string(github_flavored_markdown.Markdown([]byte(text), github_flavored_markdown.WithTOC()))
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("\ngot %q\nwant prefix %q", got, want)
+	}
+}
+
+func TestWithTOCMarker(t *testing.T) {
+	toc := `<nav class="toc">
+<ul>
+<li><a href="#a" rel="nofollow">A</a></li>
+</ul>
+</nav>
+`
+	tests := []struct {
+		text   string
+		marker string
+		want   string
+	}{
+		{
+			text:   "[TOC]\n\n# A\n",
+			marker: github_flavored_markdown.DefaultTOCMarker,
+			want:   toc + "\n" + `<h1><a name="a" class="anchor" href="#a" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>A</h1>` + "\n",
+		},
+		{
+			text:   "Intro\n\n<!-- TOC -->\n\n# A\n",
+			marker: "<!-- toc -->",
+			want:   "<p>Intro</p>\n\n" + toc + "\n" + `<h1><a name="a" class="anchor" href="#a" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>A</h1>` + "\n",
+		},
+		{
+			// Markers within other text are kept.
+			text:   "See [TOC].\n\n# A\n",
+			marker: github_flavored_markdown.DefaultTOCMarker,
+			want:   "<p>See [TOC].</p>\n\n" + `<h1><a name="a" class="anchor" href="#a" rel="nofollow" aria-hidden="true"><span class="octicon octicon-link"></span></a>A</h1>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		got := string(github_flavored_markdown.Markdown([]byte(test.text), github_flavored_markdown.WithTOCMarker(test.marker)))
+		if got != test.want {
+			t.Errorf("\ngot %q\nwant %q", got, test.want)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  string
+		found bool
+	}{
+		{text: "Intro\n\n## Sub\n\n# The *`gfm`* Package &amp; More\n\n# Other\n", want: "The gfm Package & More", found: true},
+		{text: "Setext Title\n============\n", want: "Setext Title", found: true},
+		{text: "```\n# not a heading\n```\n\n## Sub\n"},
+	}
+
+	for _, test := range tests {
+		got, found := github_flavored_markdown.Title([]byte(test.text))
+		if got != test.want || found != test.found {
+			t.Errorf("got %q, %v; want %q, %v", got, found, test.want, test.found)
+		}
+	}
+}